@@ -58,13 +58,13 @@ func TestGitFlagValidation(t *testing.T) {
 			shouldPass: true,
 		},
 		{
-			name:       "git flag with inline should pass",
-			cli:        types.CLI{ChangesOnly: true, Inline: true},
+			name:       "git flag with kinds should pass",
+			cli:        types.CLI{ChangesOnly: true, Kinds: []string{"line"}},
 			shouldPass: true,
 		},
 		{
-			name:       "git flag with block should pass",
-			cli:        types.CLI{Unstaged: true, Block: true},
+			name:       "git flag with keep-kinds should pass",
+			cli:        types.CLI{Unstaged: true, KeepKinds: []string{"doc"}},
 			shouldPass: true,
 		},
 		{
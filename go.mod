@@ -3,13 +3,13 @@ module github.com/carlosarraes/shush
 go 1.24.4
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/alecthomas/kong v1.12.0
 	github.com/fatih/color v1.18.0
+	golang.org/x/sys v0.25.0
 )
 
 require (
-	github.com/BurntSushi/toml v1.5.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	golang.org/x/sys v0.25.0 // indirect
 )
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/carlosarraes/shush/internal/types"
 )
@@ -27,7 +28,7 @@ func ValidateFlags(cli types.CLI) error {
 	}
 
 	if hookFlagCount > 0 {
-		if cli.Recursive || cli.Inline || cli.Block || cli.DryRun || cli.Backup || cli.Verbose {
+		if cli.Recursive || cli.Lang != "" || len(cli.Kinds) > 0 || len(cli.KeepKinds) > 0 || cli.OnlyDeadCode || cli.OnlyRedundant || cli.DryRun || cli.Backup || cli.Verbose {
 			return fmt.Errorf("hook commands cannot be combined with processing flags")
 		}
 
@@ -64,8 +65,35 @@ func ValidateFlags(cli types.CLI) error {
 		return fmt.Errorf("path argument is required")
 	}
 
-	if cli.Inline && cli.Block {
-		return fmt.Errorf("--inline and --block flags are mutually exclusive")
+	if cli.Lang != "" && (gitFlagCount > 0 || cli.Recursive) {
+		return fmt.Errorf("--lang applies to a single file or stdin and cannot be used with git flags or --recursive")
+	}
+
+	if cli.Path == "-" {
+		if cli.Lang == "" {
+			return fmt.Errorf("--lang is required when reading from stdin")
+		}
+		if cli.Recursive || cli.DryRun || cli.Backup {
+			return fmt.Errorf("reading from stdin cannot be combined with --recursive, --dry-run or --backup")
+		}
+	}
+
+	selections := 0
+	for _, set := range []bool{len(cli.Kinds) > 0, len(cli.KeepKinds) > 0, cli.OnlyDeadCode} {
+		if set {
+			selections++
+		}
+	}
+	if selections > 1 {
+		return fmt.Errorf("--kinds, --keep-kinds and --only-dead-code flags are mutually exclusive")
+	}
+
+	for _, kinds := range [][]string{cli.Kinds, cli.KeepKinds} {
+		for _, kind := range kinds {
+			if !types.IsCommentKind(kind) {
+				return fmt.Errorf("unknown comment kind %q (valid kinds: %s)", kind, strings.Join(types.CommentKinds, ", "))
+			}
+		}
 	}
 
 	return nil
@@ -2,8 +2,11 @@ package commands
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/processor"
 	"github.com/carlosarraes/shush/internal/types"
 )
 
@@ -22,6 +25,9 @@ func ShowConfig() error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if err := processor.RegisterLanguages(cfg); err != nil {
+		return fmt.Errorf("invalid language configuration: %w", err)
+	}
 
 	fmt.Println("Shush Configuration:")
 	if configPath != "" {
@@ -35,6 +41,30 @@ func ShowConfig() error {
 		fmt.Printf("  %2d. %s\n", i+1, pattern)
 	}
 
+	fmt.Printf("\nRemove Python docstrings: %t\n", cfg.Docstrings)
+	fmt.Printf("Empty blocks: %s\n", cfg.EmptyBlocks)
+	fmt.Printf("Keep Go exported doc comments: %t\n", cfg.KeepExportedDocs)
+	fmt.Printf("Keep license header: %t (within %d lines, keywords: %s)\n", cfg.KeepLicense, cfg.LicenseMaxLines, strings.Join(cfg.LicenseKeywords, ", "))
+	fmt.Printf("Keep shush control comments: %t\n", cfg.KeepControlComments)
+	fmt.Printf("Only dead code: %t (threshold %.2f)\n", cfg.OnlyDeadCode, cfg.DeadCodeThreshold)
+	fmt.Printf("Only redundant comments: %t (threshold %.2f)\n", cfg.OnlyRedundant, cfg.RedundantThreshold)
+	if cfg.MaxFileSize > 0 {
+		fmt.Printf("Max file size: %d bytes\n", cfg.MaxFileSize)
+	} else {
+		fmt.Println("Max file size: unlimited")
+	}
+	switch {
+	case len(cfg.Kinds) > 0:
+		fmt.Printf("Comment kinds removed: %s\n", strings.Join(cfg.Kinds, ", "))
+	case len(cfg.KeepKinds) > 0:
+		fmt.Printf("Comment kinds kept: %s\n", strings.Join(cfg.KeepKinds, ", "))
+	default:
+		fmt.Println("Comment kinds removed: all except directive")
+	}
+
+	showDirectives(cfg)
+	showLanguages(cfg)
+
 	fmt.Println("\nConfig file search order:")
 	fmt.Println("  1. .shush.toml (current directory)")
 	fmt.Println("  2. .shush.toml (git repository root)")
@@ -43,6 +73,66 @@ func ShowConfig() error {
 	return nil
 }
 
+func showDirectives(cfg *config.Config) {
+	registry := processor.DirectiveRegistry()
+	exts := make([]string, 0, len(registry))
+	for ext := range registry {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	fmt.Println("\nProtected directives:")
+	if len(cfg.StripDirectives) > 0 {
+		fmt.Printf("  Stripped tools: %s\n", strings.Join(cfg.StripDirectives, ", "))
+	}
+	for _, ext := range exts {
+		var patterns []string
+		for _, d := range registry[ext] {
+			if cfg.ProtectsDirective(d.Tool) {
+				patterns = append(patterns, fmt.Sprintf("%q (%s)", d.Pattern, d.Tool))
+			}
+		}
+		if len(patterns) > 0 {
+			fmt.Printf("  %-10s %s\n", ext+":", strings.Join(patterns, ", "))
+		}
+	}
+}
+
+func showLanguages(cfg *config.Config) {
+	names := make([]string, 0, len(cfg.Languages))
+	for name := range cfg.Languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) > 0 {
+		fmt.Println("\nCustom languages:")
+	}
+	for _, name := range names {
+		lc := cfg.Languages[name]
+		var markers []string
+		markers = append(markers, lc.LineComments...)
+		if len(lc.BlockComment) == 2 {
+			markers = append(markers, lc.BlockComment[0]+" "+lc.BlockComment[1])
+		}
+		matches := append(append([]string{}, lc.Extensions...), lc.Filenames...)
+		fmt.Printf("  %-10s %s (comments: %s)\n", name+":", strings.Join(matches, ", "), strings.Join(markers, ", "))
+	}
+
+	exts := make([]string, 0, len(cfg.Extensions))
+	for ext := range cfg.Extensions {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	if len(exts) > 0 {
+		fmt.Println("\nExtension remaps:")
+	}
+	for _, ext := range exts {
+		fmt.Printf("  .%s -> %s\n", strings.TrimPrefix(ext, "."), cfg.Extensions[ext])
+	}
+}
+
 func CreateConfig() error {
 	if err := config.CreateExampleConfig(); err != nil {
 		return fmt.Errorf("failed to create config file: %w", err)
@@ -1,16 +1,59 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/BurntSushi/toml"
+	"github.com/carlosarraes/shush/internal/types"
 )
 
 type Config struct {
-	Preserve     []string `toml:"preserve"`
-	ContextLines int      `toml:"context_lines"`
+	Preserve            []string `toml:"preserve"`
+	ContextLines        int      `toml:"context_lines"`
+	Docstrings          bool     `toml:"docstrings"`
+	EmptyBlocks         string   `toml:"empty_blocks"`
+	KeepExportedDocs    bool     `toml:"keep_exported_docs"`
+	StripDirectives     []string `toml:"strip_directives"`
+	Kinds               []string `toml:"kinds"`
+	KeepKinds           []string `toml:"keep_kinds"`
+	KeepLicense         bool     `toml:"keep_license"`
+	LicenseMaxLines     int      `toml:"license_max_lines"`
+	LicenseKeywords     []string `toml:"license_keywords"`
+	KeepControlComments bool     `toml:"keep_control_comments"`
+	OnlyDeadCode        bool     `toml:"only_dead_code"`
+	DeadCodeThreshold   float64  `toml:"dead_code_threshold"`
+	OnlyRedundant       bool     `toml:"only_redundant"`
+	RedundantThreshold  float64  `toml:"redundant_threshold"`
+	MaxFileSize         int64    `toml:"max_file_size"`
+
+	Languages  map[string]LanguageConfig `toml:"languages"`
+	Extensions map[string]string         `toml:"extensions"`
+	Overrides  []Override                `toml:"overrides"`
+
+	// Dir is the directory of the file the config was loaded from, which
+	// override patterns are relative to. It is empty for the defaults.
+	Dir string `toml:"-"`
+}
+
+// Override is an [[overrides]] entry forcing the language of the files whose
+// path matches a glob pattern.
+type Override struct {
+	Pattern  string `toml:"pattern"`
+	Language string `toml:"language"`
+}
+
+// LanguageConfig is a [languages.<name>] table describing a language shush
+// does not know, or replacing one it does.
+type LanguageConfig struct {
+	Extensions   []string `toml:"extensions"`
+	Filenames    []string `toml:"filenames"`
+	LineComments []string `toml:"line_comments"`
+	BlockComment []string `toml:"block_comment"`
+	Nested       bool     `toml:"nested"`
+	Strings      []string `toml:"strings"`
 }
 
 func Default() *Config {
@@ -28,7 +71,19 @@ func Default() *Config {
 			"mypy:",
 			"type: ignore",
 		},
-		ContextLines: 3,
+		ContextLines:        3,
+		EmptyBlocks:         "insert",
+		LicenseMaxLines:     40,
+		KeepControlComments: true,
+		DeadCodeThreshold:   0.5,
+		RedundantThreshold:  0.6,
+		MaxFileSize:         10 << 20,
+		LicenseKeywords: []string{
+			"SPDX-License-Identifier",
+			"Copyright",
+			"License",
+			"(c)",
+		},
 	}
 }
 
@@ -108,10 +163,13 @@ func findGitRoot(startDir string) string {
 
 func loadFromFile(path string) (*Config, error) {
 	config := &Config{}
-	if _, err := toml.DecodeFile(path, config); err != nil {
+	meta, err := toml.DecodeFile(path, config)
+	if err != nil {
 		return nil, err
 	}
 
+	config.Dir = filepath.Dir(path)
+
 	defaults := Default()
 	if len(config.Preserve) == 0 {
 		config.Preserve = defaults.Preserve
@@ -119,10 +177,67 @@ func loadFromFile(path string) (*Config, error) {
 	if config.ContextLines == 0 {
 		config.ContextLines = defaults.ContextLines
 	}
+	if config.EmptyBlocks == "" {
+		config.EmptyBlocks = defaults.EmptyBlocks
+	}
+	if config.LicenseMaxLines == 0 {
+		config.LicenseMaxLines = defaults.LicenseMaxLines
+	}
+	if len(config.LicenseKeywords) == 0 {
+		config.LicenseKeywords = defaults.LicenseKeywords
+	}
+	if !meta.IsDefined("keep_control_comments") {
+		config.KeepControlComments = defaults.KeepControlComments
+	}
+	if config.DeadCodeThreshold == 0 {
+		config.DeadCodeThreshold = defaults.DeadCodeThreshold
+	}
+	if config.RedundantThreshold == 0 {
+		config.RedundantThreshold = defaults.RedundantThreshold
+	}
+	if !meta.IsDefined("max_file_size") {
+		config.MaxFileSize = defaults.MaxFileSize
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
 
 	return config, nil
 }
 
+// validate rejects settings that would otherwise be silently ignored, with
+// the same rules the command line flags follow.
+func (c *Config) validate() error {
+	if c.EmptyBlocks != "insert" && c.EmptyBlocks != "keep" {
+		return fmt.Errorf("unknown empty_blocks value %q (valid values: insert, keep)", c.EmptyBlocks)
+	}
+
+	if c.MaxFileSize < 0 {
+		return fmt.Errorf("max_file_size must be a number of bytes, or 0 for no limit (got %d)", c.MaxFileSize)
+	}
+
+	selections := 0
+	for _, set := range []bool{len(c.Kinds) > 0, len(c.KeepKinds) > 0, c.OnlyDeadCode} {
+		if set {
+			selections++
+		}
+	}
+	if selections > 1 {
+		return fmt.Errorf("kinds, keep_kinds and only_dead_code are mutually exclusive")
+	}
+
+	for _, kinds := range [][]string{c.Kinds, c.KeepKinds} {
+		for _, kind := range kinds {
+			if !types.IsCommentKind(kind) {
+				return fmt.Errorf("unknown comment kind %q (valid kinds: %s)", kind, strings.Join(types.CommentKinds, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
@@ -140,6 +255,17 @@ func (c *Config) ShouldPreserveComment(comment string) bool {
 	return false
 }
 
+// ProtectsDirective reports whether directive comments read by tool are kept.
+// Listing a tool, or "*", in strip_directives turns its protection off.
+func (c *Config) ProtectsDirective(tool string) bool {
+	for _, name := range c.StripDirectives {
+		if name == "*" || strings.EqualFold(name, tool) {
+			return false
+		}
+	}
+	return true
+}
+
 func matchesPattern(text, pattern string) bool {
 
 	if strings.Contains(pattern, "*") {
@@ -209,6 +335,86 @@ preserve = [
 
 # Number of context lines to show around changes in preview mode (default: 3)
 context_lines = 3
+
+# Also remove Python docstrings (default: false)
+docstrings = false
+
+# What to do when removal would leave a Python block with no statements:
+# "insert" adds a pass, "keep" keeps the block's docstring if it had one
+empty_blocks = "insert"
+
+# Keep Go doc comments on the package clause, exported declarations and the
+# exported fields and methods of exported types (default: false)
+keep_exported_docs = false
+
+# Tool directives such as "# noqa" or "// eslint-disable" are always kept.
+# List tools here to strip their directives anyway, or "*" for all of them.
+strip_directives = []
+
+# Comment kinds to remove: line, block, doc, directive, license, code.
+# Leave empty to remove every kind except directives, or use keep_kinds instead.
+kinds = []
+# keep_kinds = ["doc", "license"]
+
+# Keep the leading comment block when it is a license or copyright header
+keep_license = false
+# The header must end within this many lines of the top of the file
+license_max_lines = 40
+# Words (case-insensitive) that make the leading block a license header
+license_keywords = ["SPDX-License-Identifier", "Copyright", "License", "(c)"]
+
+# In-source control comments: shush:off / shush:on fence a region,
+# shush:keep-next protects the next comment, and shush:ignore-file in the
+# first 5 lines skips the file. Keep the control comments themselves, so a
+# later run still honours them (default: true)
+keep_control_comments = true
+
+# Remove only commented-out code (same as --only-dead-code)
+only_dead_code = false
+# Comments scoring at least this much (0 = prose, 1 = code) count as code;
+# the preview shows each score when code is selected (--only-dead-code or a code kind)
+dead_code_threshold = 0.5
+
+# Remove only comments that repeat the code they annotate, such as
+# "// increment counter" above "counter++" (same as --only-redundant)
+only_redundant = false
+# Share of a comment's words that must appear in its code line to count as redundant
+redundant_threshold = 0.6
+
+# Largest file to process. Larger files are skipped, and larger stdin input is
+# an error (default: 10485760, 10 MiB). Use 0 for no limit.
+max_file_size = 10485760
+
+# Map extra extensions onto a known language, by extension or language name
+[extensions]
+# jsonc = "js"
+
+# Define your own languages. Strings use backslash escapes; delimiters of
+# three or more characters may span lines. A table named after a built-in
+# language key (such as "py") replaces it.
+# [languages.starlark]
+# extensions = ["star", "bzl"]
+# filenames = ["BUILD", "WORKSPACE"]
+# line_comments = ["#"]
+# strings = ['"""', "'''", '"', "'"]
+#
+# [languages.proto]
+# extensions = ["proto"]
+# line_comments = ["//"]
+# block_comment = ["/*", "*/"]
+# nested = false
+# strings = ['"', "'"]
+
+# Force a language for paths matching a glob, relative to this file. "*"
+# stays within a directory, "**" crosses directories, and a pattern without
+# a slash matches the file name anywhere. The first match wins.
+# [[overrides]]
+# pattern = "ios/**/*.h"
+# language = "objc"
+#
+# [[overrides]]
+# pattern = "deploy/nginx/*.conf"
+# language = "nginx"
 `
 
 	return os.WriteFile(".shush.toml", []byte(content), 0644)
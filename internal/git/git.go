@@ -1,7 +1,6 @@
 package git
 
 import (
-	"bufio"
 	"fmt"
 	"os/exec"
 	"regexp"
@@ -128,9 +127,10 @@ func ParseDiffUnified(diff string) ([]LineRange, error) {
 
 	headerRegex := regexp.MustCompile(`@@\s+-(\d+)(?:,(\d+))?\s+\+(\d+)(?:,(\d+))?\s+@@`)
 
-	scanner := bufio.NewScanner(strings.NewReader(diff))
-	for scanner.Scan() {
-		line := scanner.Text()
+	for line := range strings.Lines(diff) {
+		if !strings.HasPrefix(line, "@@") {
+			continue
+		}
 
 		matches := headerRegex.FindStringSubmatch(line)
 		if len(matches) > 0 {
@@ -159,7 +159,7 @@ func ParseDiffUnified(diff string) ([]LineRange, error) {
 		}
 	}
 
-	return ranges, scanner.Err()
+	return ranges, nil
 }
 
 func IsInLineRanges(lineNum int, ranges []LineRange) bool {
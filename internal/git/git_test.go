@@ -1,6 +1,7 @@
 package git
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -52,6 +53,11 @@ func TestParseGitDiffUnified(t *testing.T) {
 +new line`,
 			expected: []LineRange{{Start: 10, End: 10}},
 		},
+		{
+			name:     "line longer than a scanner buffer",
+			input:    "@@ -1,1 +1,1 @@\n-" + strings.Repeat("a", 1<<20) + "\n+" + strings.Repeat("b", 1<<20) + "\n@@ -9,0 +9,1 @@\n+x",
+			expected: []LineRange{{Start: 1, End: 1}, {Start: 9, End: 9}},
+		},
 		{
 			name:     "empty diff",
 			input:    "",
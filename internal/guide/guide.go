@@ -17,10 +17,12 @@ Shush is a fast comment removal tool for source code files using in-memory proce
 ### Basic File Processing
 ` + "```" + `bash
 shush file.py                    # Remove all comments from single file
-shush file.js --inline          # Remove only line comments (
-shush file.c --block            # Remove only block comments ( in C)
+shush file.js --kinds line      # Remove only plain line comments
+shush file.c --kinds block      # Remove only plain block comments
 shush script.sh --dry-run       # Preview changes without modification
 shush config.lua --backup       # Create backup before processing
+shush legacy.h --lang objc      # Override the detected language
+cat app.py | shush --lang python -   # Filter stdin to stdout
 ` + "```" + `
 
 ### Directory Processing  
@@ -38,11 +40,12 @@ shush project/ -r --dry-run --verbose  # 1. Preview all changes
 shush project/ -r --backup             # 2. Process with backups
 
 # Selective comment removal
-shush src/ -r --inline --backup        # Remove only line comments
-shush src/ -r --block --dry-run        # Preview block comment removal
+shush src/ -r --kinds line --backup          # Remove only plain line comments
+shush src/ -r --kinds code --dry-run          # Preview commented-out code removal
+shush src/ -r --keep-kinds doc,license        # Keep docs and license headers
 
 # Combined operations
-shush . --recursive --inline --dry-run --verbose
+shush . --recursive --kinds line --dry-run --verbose
 ` + "```" + `
 
 ## Supported Languages & Comment Types
@@ -67,6 +70,8 @@ shush . --recursive --inline --dry-run --verbose
 - **Comment-only lines**: Deleted entirely (preserves line structure)
 - **Inline comments**: Stripped but line kept (e.g., code();
 - **Block comments**: Removed (single-line or multi-line)
+- **Comment kinds**: Each comment is one of line, block, doc, directive, license, code
+- **Directives**: Tool comments (# noqa, // eslint-disable, ...) are kept unless --kinds names directive
 - **Blank lines**: Original empty lines preserved (file structure maintained)
 
 ### File Selection
@@ -79,7 +84,7 @@ shush . --recursive --inline --dry-run --verbose
 
 ### Mutually Exclusive
 ` + "```" + `bash
-shush file.js --inline --block   # ❌ ERROR: Cannot use both
+shush file.js --kinds line --keep-kinds doc   # ❌ ERROR: Cannot use both
 ` + "```" + `
 
 ### Recommended Workflows
@@ -91,8 +96,8 @@ shush project/ -r --dry-run --verbose
 shush project/ -r --backup --verbose
 
 # Selective processing
-shush src/ -r --inline --dry-run    # Preview line comment removal
-shush src/ -r --inline --backup     # Apply line comment removal
+shush src/ -r --kinds line --dry-run    # Preview line comment removal
+shush src/ -r --kinds line --backup     # Apply line comment removal
 ` + "```" + `
 
 ## Output Modes
@@ -128,7 +133,7 @@ shush --staged --dry-run --verbose   # Preview staged changes with details
 
 # Process only unstaged changes  
 shush --unstaged                     # Clean comments from unstaged work
-shush --unstaged --inline           # Remove only line comments from unstaged files
+shush --unstaged --kinds line       # Remove only line comments from unstaged files
 ` + "```" + `
 
 ### Git Workflow Examples
@@ -140,7 +145,7 @@ git commit -m "Clean implementation" # 3. Commit cleaned code
 
 # Feature development cleanup
 shush --unstaged --dry-run           # 1. Preview unstaged work cleanup  
-shush --unstaged --inline            # 2. Remove only debug comments
+shush --unstaged --kinds code        # 2. Remove only commented-out code
 shush --changes-only                 # 3. Clean all changes before review
 
 # Safe exploration workflow
@@ -159,7 +164,7 @@ shush --staged --backup --verbose         # Process with maximum safety
 - **Mutually exclusive**: Cannot combine --staged, --unstaged, --changes-only
 - **No explicit paths**: Git flags work on repository scope, not individual files
 - **No recursive flag**: Git mode handles repository traversal automatically
-- **Compatible with**: --inline, --block, --dry-run, --backup, --verbose
+- **Compatible with**: --kinds, --keep-kinds, --dry-run, --backup, --verbose
 
 ### Git Error Scenarios
 - **Not in repository**: Clear error message when git flags used outside git repo
@@ -185,7 +190,7 @@ shush --staged --backup --verbose         # Process with maximum safety
 1. **Essential**: Basic file processing (shush file.py)
 2. **Important**: Directory processing (shush src/ -r)
 3. **Safety**: Dry-run and backup modes (--dry-run, --backup)
-4. **Selective**: Comment type filtering (--inline, --block)
+4. **Selective**: Comment kind filtering (--kinds, --keep-kinds)
 
 Shush excels at safe, fast comment removal with excellent preview capabilities for confident code processing.
 `)
@@ -0,0 +1,152 @@
+package processor
+
+import (
+	"strings"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+// classification is the class of a comment as named by --kinds, plus the tool
+// that reads it when it is a directive. score is the comment's code score for
+// comments that were tested for commented-out code.
+type classification struct {
+	kind   string
+	tool   string
+	score  float64
+	scored bool
+}
+
+// classifyComments sorts every comment into one kind. A directive wins over
+// everything else, then the license header, then doc comments and commented
+// out code, which is any comment whose code score reaches the configured
+// threshold; whatever is left is a plain line or block comment. line is the
+// number of lines before src in its file, which is only nonzero for the later
+// segments of a streamed file.
+func classifyComments(src string, line int, comments []comment, language types.Language, cfg *config.Config) []classification {
+	classes := make([]classification, len(comments))
+	for k, c := range comments {
+		if c.keep != "" {
+			classes[k] = classification{kind: "directive"}
+		} else if tool := directiveTool(c, src, line, language, cfg); tool != "" {
+			classes[k] = classification{kind: "directive", tool: tool}
+		}
+	}
+
+	if line == 0 {
+		for _, k := range licenseHeader(src, comments, classes, cfg) {
+			classes[k].kind = "license"
+		}
+	}
+
+	for k, c := range comments {
+		if classes[k].kind != "" {
+			continue
+		}
+		text := src[c.start:c.end]
+		if c.kind == docstringComment || c.doc || isDocComment(text, language.DocComments) {
+			classes[k].kind = "doc"
+			continue
+		}
+
+		classes[k].score = codeScore(commentBody(text, c, language), language.Keywords)
+		classes[k].scored = true
+		switch {
+		case classes[k].score >= cfg.DeadCodeThreshold:
+			classes[k].kind = "code"
+		case c.kind == blockComment:
+			classes[k].kind = "block"
+		default:
+			classes[k].kind = "line"
+		}
+	}
+
+	return classes
+}
+
+// licenseHeader returns the comments of the first comment block in the file,
+// after any shebang and directives, when that block ends within the
+// configured number of lines and mentions a license keyword. A block is one
+// block comment or a run of line comments on consecutive lines.
+func licenseHeader(src string, comments []comment, classes []classification, cfg *config.Config) []int {
+	var block []int
+	prevEnd := 0
+	if strings.HasPrefix(src, "#!") {
+		prevEnd = len(src)
+		if end := strings.IndexByte(src, '\n'); end != -1 {
+			prevEnd = end
+		}
+	}
+	for k, c := range comments {
+		if c.start < prevEnd {
+			continue
+		}
+		gap := src[prevEnd:c.start]
+		if strings.TrimSpace(gap) != "" {
+			break
+		}
+		if classes[k].kind == "directive" && len(block) == 0 {
+			prevEnd = c.end
+			continue
+		}
+		if len(block) > 0 && (strings.Count(gap, "\n") > 1 || c.kind != lineComment || comments[block[0]].kind != lineComment) {
+			break
+		}
+		block = append(block, k)
+		prevEnd = c.end
+	}
+
+	if len(block) == 0 || strings.Count(src[:prevEnd], "\n") >= cfg.LicenseMaxLines {
+		return nil
+	}
+
+	for _, k := range block {
+		text := strings.ToLower(src[comments[k].start:comments[k].end])
+		for _, keyword := range cfg.LicenseKeywords {
+			if strings.Contains(text, strings.ToLower(keyword)) {
+				return block
+			}
+		}
+	}
+	return nil
+}
+
+// isDocComment reports whether text opens with one of the language's doc
+// comment markers. A marker followed by its own last byte again, or by a
+// slash, is a banner such as "////" or "/**/" rather than documentation.
+func isDocComment(text string, markers []string) bool {
+	for _, marker := range markers {
+		if !strings.HasPrefix(text, marker) {
+			continue
+		}
+		if len(text) > len(marker) {
+			next := text[len(marker)]
+			if next == marker[len(marker)-1] || next == '/' {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// commentBody strips the comment markers from text.
+func commentBody(text string, c comment, language types.Language) string {
+	if c.kind == blockComment && language.BlockComment != nil {
+		text = strings.TrimPrefix(text, language.BlockComment.Start)
+		if language.BlockComment.LongBracket {
+			level := strings.IndexByte(text[1:], '[')
+			text = strings.TrimSuffix(text[level+2:], "]"+strings.Repeat("=", level)+"]")
+		} else {
+			text = strings.TrimSuffix(text, language.BlockComment.End)
+		}
+	} else {
+		for _, marker := range []string{language.LineComment, language.AlternateLineComment} {
+			if marker != "" && strings.HasPrefix(text, marker) {
+				text = strings.TrimLeft(text[len(marker):], marker[len(marker)-1:])
+				break
+			}
+		}
+	}
+	return strings.TrimSpace(text)
+}
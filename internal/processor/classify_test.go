@@ -0,0 +1,175 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+func TestClassifyComments(t *testing.T) {
+	src := strings.Join([]string{
+		"#!/usr/bin/env node",
+		"// Copyright 2024 Example Inc.",
+		"// SPDX-License-Identifier: MIT",
+		"",
+		"/** Adds two numbers. */",
+		"function add(a, b) {",
+		"  // const old = a - b;",
+		"  return a + b; // the sum",
+		"  /* note */",
+		"}",
+		"// eslint-disable-next-line",
+	}, "\n")
+
+	language, err := DetectLanguage("x.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	comments := newLexer(language).scan(src)
+	classes := classifyComments(src, 0, comments, language, config.Default())
+
+	var got []string
+	for _, class := range classes {
+		got = append(got, class.kind)
+	}
+	expected := []string{"license", "license", "doc", "code", "line", "block", "directive"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("classifyComments() = %v, want %v", got, expected)
+	}
+}
+
+func TestStripCommentsKinds(t *testing.T) {
+	src := "/** doc */\nx(); // note\n// y();\n/* block */"
+
+	tests := []struct {
+		name     string
+		cli      types.CLI
+		cfgKinds []string
+		expected string
+	}{
+		{
+			name:     "all kinds by default",
+			expected: "\nx();\n\n",
+		},
+		{
+			name:     "only code",
+			cli:      types.CLI{Kinds: []string{"code"}},
+			expected: "/** doc */\nx(); // note\n\n/* block */",
+		},
+		{
+			name:     "keep doc",
+			cli:      types.CLI{KeepKinds: []string{"doc"}},
+			expected: "/** doc */\nx();\n\n",
+		},
+		{
+			name:     "config selection",
+			cfgKinds: []string{"line", "block"},
+			expected: "/** doc */\nx();\n// y();\n",
+		},
+		{
+			name:     "flags replace config",
+			cli:      types.CLI{Kinds: []string{"doc"}},
+			cfgKinds: []string{"line", "block"},
+			expected: "\nx(); // note\n// y();\n/* block */",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Kinds = tt.cfgKinds
+			p := &Processor{cli: tt.cli}
+			p.cli.PreserveLines = true
+			result := p.stripComments(strings.Split(src, "\n"), languageMap["js"], cfg, nil)
+			got := strings.Join(result.output, "\n")
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStripCommentsLicenseHeader(t *testing.T) {
+	header := "/*\n * Copyright 2024 Example Inc.\n *\n * Licensed under the Apache License, Version 2.0.\n */\n"
+
+	tests := []struct {
+		name      string
+		src       string
+		keep      bool
+		maxLines  int
+		keywords  []string
+		expected  string
+		preserved int
+	}{
+		{
+			name:      "header kept whole",
+			src:       header + "// comment\nint x;",
+			keep:      true,
+			expected:  header + "int x;",
+			preserved: 5,
+		},
+		{
+			name:      "line comment header after shebang",
+			src:       "#!/usr/bin/env tcc\n// SPDX-License-Identifier: MIT\n// Copyright (c) Someone\n\n// comment\nint x;",
+			keep:      true,
+			expected:  "#!/usr/bin/env tcc\n// SPDX-License-Identifier: MIT\n// Copyright (c) Someone\n\nint x;",
+			preserved: 2,
+		},
+		{
+			name:     "mode off",
+			src:      header + "int x;",
+			expected: "int x;",
+		},
+		{
+			name:     "no keyword",
+			src:      "// Helpers for x.\nint x;",
+			keep:     true,
+			expected: "int x;",
+		},
+		{
+			name:     "header beyond line limit",
+			src:      header + "int x;",
+			keep:     true,
+			maxLines: 3,
+			expected: "int x;",
+		},
+		{
+			name:      "custom keywords",
+			src:       "// Proprietary and confidential.\nint x;",
+			keep:      true,
+			keywords:  []string{"proprietary"},
+			expected:  "// Proprietary and confidential.\nint x;",
+			preserved: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.KeepLicense = tt.keep
+			if tt.maxLines != 0 {
+				cfg.LicenseMaxLines = tt.maxLines
+			}
+			if tt.keywords != nil {
+				cfg.LicenseKeywords = tt.keywords
+			}
+
+			p := &Processor{}
+			result := p.stripComments(strings.Split(tt.src, "\n"), languageMap["c"], cfg, nil)
+			got := strings.Join(result.output, "\n")
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+			if result.preserved != tt.preserved {
+				t.Errorf("preserved = %d, want %d", result.preserved, tt.preserved)
+			}
+			for _, change := range result.changes {
+				if change.changeType == "preserved" && change.note != "license header" {
+					t.Errorf("line %d preserved with note %q", change.lineNum, change.note)
+				}
+			}
+		})
+	}
+}
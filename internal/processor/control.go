@@ -0,0 +1,94 @@
+package processor
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ignoreFileLines is how far from the top of a file shush:ignore-file is
+// honoured.
+const ignoreFileLines = 5
+
+// controlDirective returns the shush control word that opens a comment's
+// body, such as "off" for "// shush:off", or "" when there is none.
+func controlDirective(text string) string {
+	i := strings.Index(text, "shush:")
+	if i == -1 || !atBodyStart(text, i) {
+		return ""
+	}
+	word := text[i+len("shush:"):]
+	end := 0
+	for end < len(word) && (word[end] == '-' || word[end] >= 'a' && word[end] <= 'z') {
+		end++
+	}
+
+	switch word[:end] {
+	case "off", "on", "keep", "keep-next", "ignore-file":
+		return word[:end]
+	}
+	return ""
+}
+
+// atBodyStart reports whether text[i:] opens the body of the comment text:
+// only comment markers, punctuation and whitespace come before it.
+func atBodyStart(text string, i int) bool {
+	return strings.IndexFunc(text[:i], func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) == -1
+}
+
+// controlRegions applies the shush control comments. It returns, for every
+// comment, the reason it is protected by a shush:off region or a preceding
+// shush:keep-next, and whether the comment is itself a control comment. The
+// state starts from seg and is left there for the next segment.
+func controlRegions(src string, comments []comment, seg *segment) (reasons []string, controls []bool) {
+	reasons = make([]string, len(comments))
+	controls = make([]bool, len(comments))
+
+	off, keepNext := seg.off, seg.keepNext
+	for k, c := range comments {
+		directive := controlDirective(src[c.start:c.end])
+		if directive != "" {
+			controls[k] = true
+		}
+
+		switch directive {
+		case "off":
+			off = true
+			continue
+		case "on":
+			off = false
+			continue
+		case "keep", "keep-next":
+			keepNext = true
+			continue
+		case "ignore-file":
+			continue
+		}
+
+		switch {
+		case off:
+			reasons[k] = "shush:off"
+		case keepNext:
+			reasons[k] = "shush:keep-next"
+		}
+		keepNext = false
+	}
+
+	seg.off, seg.keepNext = off, keepNext
+	return reasons, controls
+}
+
+// ignoresFile reports whether a shush:ignore-file comment appears within the
+// first ignoreFileLines lines of src.
+func ignoresFile(src string, comments []comment) bool {
+	for _, c := range comments {
+		if strings.Count(src[:c.start], "\n") >= ignoreFileLines {
+			break
+		}
+		if controlDirective(src[c.start:c.end]) == "ignore-file" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+)
+
+func TestStripCommentsControlDirectives(t *testing.T) {
+	tests := []struct {
+		name     string
+		ext      string
+		src      string
+		keep     bool
+		scope    func(lineNum int) bool
+		expected string
+		ignored  bool
+	}{
+		{
+			name:     "off and on fence a region",
+			ext:      "js",
+			src:      "// a\n// shush:off\nx(); // b\n// shush:on\ny(); // c",
+			expected: "x(); // b\ny();",
+		},
+		{
+			name:     "off without on runs to the end",
+			ext:      "py",
+			src:      "# shush:off\nx = 1  # a\n# b",
+			expected: "x = 1  # a\n# b",
+		},
+		{
+			name:     "keep-next protects one comment",
+			ext:      "py",
+			src:      "# shush:keep-next\n# kept\n# removed\nx = 1",
+			expected: "# kept\nx = 1",
+		},
+		{
+			name:     "control comments kept on request",
+			ext:      "sql",
+			src:      "-- shush:off\n-- a\n-- shush:on\n-- b\nSELECT 1;",
+			keep:     true,
+			expected: "-- shush:off\n-- a\n-- shush:on\nSELECT 1;",
+		},
+		{
+			name:     "control word must open the comment",
+			ext:      "js",
+			src:      "// see shush:off in the docs\nx(); // a\n/* shush:keep-next */\n// kept",
+			expected: "x();\n// kept",
+		},
+		{
+			name:     "ignore-file near the top",
+			ext:      "hs",
+			src:      "{- shush:ignore-file -}\nmain = pure () -- a",
+			expected: "{- shush:ignore-file -}\nmain = pure () -- a",
+			ignored:  true,
+		},
+		{
+			name:     "ignore-file too far down",
+			ext:      "rb",
+			src:      "a = 1\nb = 2\nc = 3\nd = 4\ne = 5\n# shush:ignore-file\nf = 6 # x",
+			expected: "a = 1\nb = 2\nc = 3\nd = 4\ne = 5\nf = 6",
+		},
+		{
+			name:     "region applies outside the changed lines",
+			ext:      "go",
+			src:      "package p\n\n// shush:off\nvar x = 1 // a\nvar y = 2 // b",
+			scope:    func(lineNum int) bool { return lineNum >= 4 },
+			expected: "package p\n\n// shush:off\nvar x = 1 // a\nvar y = 2 // b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.KeepControlComments = tt.keep
+
+			p := &Processor{}
+			result := p.stripComments(strings.Split(tt.src, "\n"), languageMap[tt.ext], cfg, tt.scope)
+			got := strings.Join(result.output, "\n")
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+			if result.ignored != tt.ignored {
+				t.Errorf("ignored = %t, want %t", result.ignored, tt.ignored)
+			}
+		})
+	}
+}
+
+func TestStripCommentsControlDirectivesRerun(t *testing.T) {
+	src := "// shush:off\nx(); // fenced\n// shush:on\ny(); // removed"
+	expected := "// shush:off\nx(); // fenced\n// shush:on\ny();"
+
+	p := &Processor{}
+	lines := strings.Split(src, "\n")
+	for run := 1; run <= 2; run++ {
+		lines = p.stripComments(lines, languageMap["js"], config.Default(), nil).output
+		if got := strings.Join(lines, "\n"); got != expected {
+			t.Fatalf("run %d: stripComments() = %q, want %q", run, got, expected)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+// RegisterLanguages adds the [languages.<name>] tables, the [extensions]
+// remaps and the [[overrides]] of cfg to the language registry. Each language
+// is registered under its lower-cased name, which [extensions] and
+// [[overrides]] entries may then point at.
+func RegisterLanguages(cfg *config.Config) error {
+	names := make([]string, 0, len(cfg.Languages))
+	for name := range cfg.Languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		language, err := customLanguage(cfg.Languages[name])
+		if err != nil {
+			return fmt.Errorf("languages.%s: %w", name, err)
+		}
+		key := strings.ToLower(name)
+		languageMap[key] = language
+		if _, ok := languageNames[key]; !ok {
+			languageNames[key] = name
+		}
+
+		for _, ext := range cfg.Languages[name].Extensions {
+			ext = normalizeExtension(ext)
+			if ext == "" {
+				return fmt.Errorf("languages.%s: empty extension", name)
+			}
+			extensionLanguages[ext] = key
+		}
+		for _, filename := range cfg.Languages[name].Filenames {
+			if filename == "" || strings.ContainsRune(filename, '/') {
+				return fmt.Errorf("languages.%s: invalid filename %q", name, filename)
+			}
+			filenameLanguages[filename] = key
+		}
+	}
+
+	remaps := make(map[string]string, len(cfg.Extensions))
+	exts := make([]string, 0, len(cfg.Extensions))
+	for ext, target := range cfg.Extensions {
+		from := normalizeExtension(ext)
+		if from == "" {
+			return fmt.Errorf("extensions: empty extension")
+		}
+		remaps[from] = strings.ToLower(strings.TrimPrefix(target, "."))
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	resolved := make(map[string]string, len(exts))
+	for _, ext := range exts {
+		key, err := resolveRemap(normalizeExtension(ext), remaps)
+		if err != nil {
+			return fmt.Errorf("extensions.%s: %w", ext, err)
+		}
+		resolved[normalizeExtension(ext)] = key
+	}
+	for ext, key := range resolved {
+		extensionLanguages[ext] = key
+	}
+
+	languageOverrides = nil
+	for i, o := range cfg.Overrides {
+		if o.Pattern == "" {
+			return fmt.Errorf("overrides[%d]: empty pattern", i)
+		}
+		pattern, err := globPattern(o.Pattern)
+		if err != nil {
+			return fmt.Errorf("overrides[%d]: %w", i, err)
+		}
+		key, err := lookupLanguage(o.Language)
+		if err != nil {
+			return fmt.Errorf("overrides[%d]: %w", i, err)
+		}
+		languageOverrides = append(languageOverrides, languageOverride{pattern: pattern, dir: cfg.Dir, key: key})
+	}
+
+	return nil
+}
+
+// resolveRemap follows the [extensions] remap of ext, through any further
+// remaps its target names, to a languageMap key.
+func resolveRemap(ext string, remaps map[string]string) (string, error) {
+	seen := map[string]bool{ext: true}
+	to := remaps[ext]
+	for next, ok := remaps[to]; ok; next, ok = remaps[to] {
+		if seen[to] {
+			return "", fmt.Errorf("remap loop through %q", to)
+		}
+		seen[to] = true
+		to = next
+	}
+
+	if alias, ok := extensionLanguages[to]; ok {
+		to = alias
+	}
+	if _, ok := languageMap[to]; !ok {
+		return "", fmt.Errorf("unknown language %q", to)
+	}
+	return to, nil
+}
+
+func customLanguage(lc config.LanguageConfig) (types.Language, error) {
+	var language types.Language
+
+	switch len(lc.LineComments) {
+	case 2:
+		language.AlternateLineComment = lc.LineComments[1]
+		fallthrough
+	case 1:
+		language.LineComment = lc.LineComments[0]
+	case 0:
+	default:
+		return language, fmt.Errorf("line_comments takes at most 2 markers, got %d", len(lc.LineComments))
+	}
+	for _, marker := range lc.LineComments {
+		if strings.TrimSpace(marker) == "" {
+			return language, fmt.Errorf("line_comments: empty marker")
+		}
+	}
+
+	if lc.BlockComment != nil {
+		if len(lc.BlockComment) != 2 || lc.BlockComment[0] == "" || lc.BlockComment[1] == "" {
+			return language, fmt.Errorf("block_comment must be a start and an end marker, like [\"/*\", \"*/\"]")
+		}
+		language.BlockComment = &types.BlockComment{Start: lc.BlockComment[0], End: lc.BlockComment[1], Nested: lc.Nested}
+	} else if lc.Nested {
+		return language, fmt.Errorf("nested is set but there is no block_comment")
+	}
+
+	if language.LineComment == "" && language.BlockComment == nil {
+		return language, fmt.Errorf("needs line_comments or block_comment")
+	}
+
+	if lc.Strings != nil {
+		language.Strings = []types.StringLiteral{}
+		for _, delimiter := range lc.Strings {
+			if delimiter == "" {
+				return language, fmt.Errorf("strings: empty delimiter")
+			}
+			language.Strings = append(language.Strings, types.StringLiteral{
+				Start:     delimiter,
+				End:       delimiter,
+				Escape:    '\\',
+				Multiline: len(delimiter) >= 3,
+			})
+		}
+		sort.SliceStable(language.Strings, func(i, j int) bool {
+			return len(language.Strings[i].Start) > len(language.Strings[j].Start)
+		})
+	}
+
+	return language, nil
+}
+
+func normalizeExtension(ext string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+}
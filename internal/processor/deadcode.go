@@ -0,0 +1,212 @@
+package processor
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+var (
+	cKeywords          = []string{"if", "else", "for", "while", "do", "switch", "case", "return", "break", "continue", "goto", "struct", "union", "enum", "typedef", "static", "const", "int", "char", "void", "unsigned", "long", "float", "double", "sizeof", "#include", "#define", "#if", "#ifdef", "#endif"}
+	cppKeywords        = []string{"class", "namespace", "template", "typename", "public:", "private:", "protected:", "virtual", "auto", "new", "delete", "using", "std::", "throw", "try", "catch"}
+	goKeywords         = []string{"if", "else", "for", "range", "switch", "case", "return", "break", "continue", "func", "var", "const", "type", "struct", "interface", "package", "import", "go", "defer", "select", "chan", "map", "fmt."}
+	jsKeywords         = []string{"if", "else", "for", "while", "do", "switch", "case", "return", "break", "continue", "function", "var", "let", "const", "class", "new", "import", "export", "await", "async", "throw", "try", "catch", "console.", "this.", "typeof"}
+	tsKeywords         = []string{"interface", "type", "enum", "implements", "readonly", "declare", "namespace"}
+	pyKeywords         = []string{"if", "elif", "else:", "for", "while", "def", "class", "return", "import", "from", "with", "try:", "except", "finally:", "raise", "lambda", "yield", "pass", "print(", "self."}
+	rubyKeywords       = []string{"if", "elsif", "else", "unless", "while", "until", "def", "class", "module", "end", "return", "require", "puts", "yield", "do", "begin", "rescue", "@"}
+	shellKeywords      = []string{"if", "then", "elif", "else", "fi", "for", "while", "do", "done", "case", "esac", "function", "echo", "export", "local", "return", "source", "cd", "$"}
+	jvmKeywords        = []string{"if", "else", "for", "while", "do", "switch", "case", "return", "break", "continue", "class", "interface", "public", "private", "protected", "static", "final", "new", "import", "package", "throw", "try", "catch", "val", "var", "fun", "override", "this.", "System."}
+	csharpKeywords     = []string{"if", "else", "for", "foreach", "while", "switch", "case", "return", "class", "public", "private", "static", "var", "new", "using", "namespace", "throw", "try", "catch", "await", "Console."}
+	rustKeywords       = []string{"if", "else", "for", "while", "loop", "match", "return", "fn", "let", "mut", "struct", "enum", "impl", "trait", "use", "mod", "pub", "println!", "self.", "unsafe"}
+	swiftKeywords      = []string{"if", "else", "for", "while", "switch", "case", "return", "func", "let", "var", "struct", "class", "enum", "import", "guard", "self.", "print("}
+	dartKeywords       = []string{"if", "else", "for", "while", "switch", "case", "return", "class", "final", "var", "void", "import", "await", "async", "new", "print("}
+	luaKeywords        = []string{"if", "then", "elseif", "else", "end", "for", "while", "do", "repeat", "until", "function", "local", "return", "require", "print("}
+	phpKeywords        = []string{"if", "else", "foreach", "for", "while", "switch", "case", "return", "function", "class", "public", "private", "echo", "new", "use", "namespace", "$"}
+	perlKeywords       = []string{"if", "elsif", "else", "unless", "foreach", "for", "while", "sub", "my", "our", "return", "use", "print", "$", "@"}
+	sqlKeywords        = []string{"SELECT", "INSERT", "UPDATE", "DELETE", "FROM", "WHERE", "JOIN", "CREATE", "ALTER", "DROP", "GROUP BY", "ORDER BY", "select", "insert", "update", "delete", "from", "where"}
+	haskellKeywords    = []string{"let", "in", "where", "case", "of", "if", "then", "else", "data", "type", "newtype", "class", "instance", "import", "module", "do"}
+	lispKeywords       = []string{"(defun", "(define", "(let", "(setq", "(if", "(cond", "(lambda", "(defn", "(def", "(ns"}
+	cssKeywords        = []string{"color:", "margin", "padding", "display:", "width:", "height:", "font", "background", "border", "@media", "@import"}
+	markupKeywords     = []string{"<div", "<span", "<p>", "<a ", "<img", "<script", "<link", "<meta", "</"}
+	configKeywords     = []string{"="}
+	yamlKeywords       = []string{": ", "- "}
+	powershellKeywords = []string{"if", "else", "foreach", "function", "param", "return", "Write-Host", "Get-", "Set-", "$"}
+	rKeywords          = []string{"if", "else", "for", "while", "function", "return", "library(", "<-"}
+	matlabKeywords     = []string{"if", "elseif", "else", "end", "for", "while", "switch", "case", "otherwise", "function", "return", "disp(", "fprintf("}
+	ocamlKeywords      = []string{"let", "in", "match", "with", "fun", "function", "if", "then", "else", "type", "module", "open"}
+)
+
+// keywordMap holds the words that suggest code in each language, keyed like
+// languageMap.
+var keywordMap = map[string][]string{
+	"lua":  luaKeywords,
+	"py":   pyKeywords,
+	"sh":   shellKeywords,
+	"bash": shellKeywords,
+	"zsh":  shellKeywords,
+	"fish": shellKeywords,
+	"ps1":  powershellKeywords,
+	"r":    rKeywords,
+
+	"js":     jsKeywords,
+	"ts":     keywordSet(jsKeywords, tsKeywords),
+	"jsx":    keywordSet(jsKeywords, markupKeywords),
+	"tsx":    keywordSet(jsKeywords, tsKeywords, markupKeywords),
+	"go":     goKeywords,
+	"c":      cKeywords,
+	"cpp":    keywordSet(cKeywords, cppKeywords),
+	"cc":     keywordSet(cKeywords, cppKeywords),
+	"cxx":    keywordSet(cKeywords, cppKeywords),
+	"h":      keywordSet(cKeywords, cppKeywords),
+	"hpp":    keywordSet(cKeywords, cppKeywords),
+	"objc":   cKeywords,
+	"mm":     keywordSet(cKeywords, cppKeywords),
+	"java":   jvmKeywords,
+	"cs":     csharpKeywords,
+	"rs":     rustKeywords,
+	"swift":  swiftKeywords,
+	"kt":     jvmKeywords,
+	"kts":    jvmKeywords,
+	"dart":   dartKeywords,
+	"scala":  jvmKeywords,
+	"groovy": jvmKeywords,
+	"gradle": jvmKeywords,
+	"hs":     haskellKeywords,
+	"ml":     ocamlKeywords,
+	"mli":    ocamlKeywords,
+
+	"lisp": lispKeywords,
+	"scm":  lispKeywords,
+	"el":   lispKeywords,
+	"clj":  lispKeywords,
+	"cljs": lispKeywords,
+	"cljc": lispKeywords,
+
+	"php": phpKeywords,
+
+	"css":  cssKeywords,
+	"scss": cssKeywords,
+	"sass": cssKeywords,
+	"less": cssKeywords,
+
+	"html": markupKeywords,
+	"htm":  markupKeywords,
+	"xml":  markupKeywords,
+	"svg":  markupKeywords,
+
+	"vue":    keywordSet(jsKeywords, tsKeywords, markupKeywords),
+	"svelte": keywordSet(jsKeywords, tsKeywords, markupKeywords),
+	"astro":  keywordSet(jsKeywords, tsKeywords, markupKeywords),
+
+	"rb":   rubyKeywords,
+	"pl":   perlKeywords,
+	"yml":  yamlKeywords,
+	"yaml": yamlKeywords,
+	"toml": configKeywords,
+	"ini":  configKeywords,
+	"conf": configKeywords,
+	"cfg":  configKeywords,
+
+	"sql": sqlKeywords,
+
+	"matlab": matlabKeywords,
+
+	"env": configKeywords,
+
+	"dockerfile": {"RUN", "FROM", "COPY", "ADD", "ENV", "WORKDIR", "CMD", "ENTRYPOINT", "EXPOSE"},
+	"makefile":   {"$(", ":="},
+}
+
+func keywordSet(sets ...[]string) []string {
+	var all []string
+	for _, set := range sets {
+		all = append(all, set...)
+	}
+	return all
+}
+
+var (
+	assignmentPattern = regexp.MustCompile(`^[\w.$@\[\]"']+\s*(=|:=|\+=|-=|\*=|/=|<-)\s*[^=\s]`)
+	callPattern       = regexp.MustCompile(`[\w.$>]\(.*\)\s*[;,:]?$`)
+	operatorPattern   = regexp.MustCompile(`==|!=|&&|\|\||=>|->|::|\+\+|<=|>=|\[\]|\w\[\w*\]`)
+	proseWordPattern  = regexp.MustCompile(`^[A-Za-z']+[,]?$`)
+)
+
+// codeScore rates how much a comment body reads like source code, from 0
+// for prose to 1 for code. Each non-empty line is scored from its token
+// shapes and the language's keywords, and the comment gets the average.
+func codeScore(body string, keywords []string) float64 {
+	total, lines := 0.0, 0
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "*"))
+		if line == "" {
+			continue
+		}
+		total += lineCodeScore(line, keywords)
+		lines++
+	}
+	if lines == 0 {
+		return 0
+	}
+	return total / float64(lines)
+}
+
+func lineCodeScore(line string, keywords []string) float64 {
+	score := 0.0
+
+	switch {
+	case strings.HasSuffix(line, ";"):
+		score += 0.6
+	case line == "{" || line == "}" || line == ")" || line == "});" || strings.HasSuffix(line, "{"):
+		score += 0.6
+	case strings.HasSuffix(line, "}") && strings.Contains(line, "{"):
+		score += 0.4
+	}
+
+	if assignmentPattern.MatchString(line) {
+		score += 0.5
+	}
+	if callPattern.MatchString(line) {
+		score += 0.4
+	}
+	words := strings.Fields(line)
+	if startsWithKeyword(line, keywords) {
+		score += 0.4
+		if len(words) <= 4 && strings.ContainsAny(line, ",()[]{}=<>:;+-*/&|!") {
+			score += 0.2
+		}
+	}
+	if operatorPattern.MatchString(line) {
+		score += 0.2
+	}
+
+	prose := 0
+	for _, word := range words {
+		if proseWordPattern.MatchString(word) {
+			prose++
+		}
+	}
+	if len(words) >= 4 && prose*4 >= len(words)*3 {
+		score -= 0.4
+	}
+	if strings.HasSuffix(line, ".") || strings.HasSuffix(line, "?") || strings.HasSuffix(line, "!") {
+		score -= 0.4
+	}
+	if strings.Contains(line, ". ") || strings.Contains(line, ", ") && len(words) >= 4 && prose*2 > len(words) {
+		score -= 0.2
+	}
+
+	return math.Min(math.Max(score, 0), 1)
+}
+
+func startsWithKeyword(line string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if !strings.HasPrefix(line, keyword) {
+			continue
+		}
+		if len(line) == len(keyword) || !isIdentByte(keyword[len(keyword)-1]) || !isIdentByte(line[len(keyword)]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+func TestCodeScore(t *testing.T) {
+	tests := []struct {
+		ext  string
+		body string
+		code bool
+	}{
+		{ext: "js", body: "const total = items.length;", code: true},
+		{ext: "js", body: "console.log(total)", code: true},
+		{ext: "js", body: "if (x > 1) {", code: true},
+		{ext: "js", body: "}", code: true},
+		{ext: "go", body: "return nil, err", code: true},
+		{ext: "py", body: "x = compute(a, b)", code: true},
+		{ext: "py", body: "def helper(value):", code: true},
+		{ext: "c", body: "free(buf);\nbuf = NULL;", code: true},
+		{ext: "js", body: "Make sure the cache is warm before calling this.", code: false},
+		{ext: "go", body: "if you change this, update the docs too", code: false},
+		{ext: "py", body: "See the README for details", code: false},
+		{ext: "c", body: "Returns the number of bytes written, or -1 on error.", code: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			language, err := DetectLanguage("x." + tt.ext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			score := codeScore(tt.body, language.Keywords)
+			if got := score >= config.Default().DeadCodeThreshold; got != tt.code {
+				t.Errorf("codeScore(%q) = %.2f, want code = %t", tt.body, score, tt.code)
+			}
+		})
+	}
+}
+
+func TestStripCommentsOnlyDeadCode(t *testing.T) {
+	src := "// Keep the total in sync.\n// total = 0;\nrun(); // run(old);"
+	language, err := DetectLanguage("x.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Processor{cli: types.CLI{OnlyDeadCode: true}}
+	result := p.stripComments(strings.Split(src, "\n"), language, config.Default(), nil)
+
+	expected := "// Keep the total in sync.\nrun();"
+	if got := strings.Join(result.output, "\n"); got != expected {
+		t.Errorf("stripComments() = %q, want %q", got, expected)
+	}
+
+	notes := map[int]string{}
+	for _, change := range result.changes {
+		notes[change.lineNum] = change.note
+	}
+	if !strings.HasPrefix(notes[1], "prose ") {
+		t.Errorf("line 1 note = %q, want a prose score", notes[1])
+	}
+	for _, line := range []int{2, 3} {
+		if !strings.HasPrefix(notes[line], "code ") {
+			t.Errorf("line %d note = %q, want a code score", line, notes[line])
+		}
+	}
+
+	result = (&Processor{}).stripComments(strings.Split(src, "\n"), language, config.Default(), nil)
+	for _, change := range result.changes {
+		if change.note != "" {
+			t.Errorf("line %d note = %q without code selected, want none", change.lineNum, change.note)
+		}
+	}
+}
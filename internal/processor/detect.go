@@ -0,0 +1,344 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// filenameLanguages maps file names that carry no usable extension to their
+// languageMap key.
+var filenameLanguages = map[string]string{
+	"Dockerfile":    "dockerfile",
+	"Containerfile": "dockerfile",
+	"Makefile":      "makefile",
+	"makefile":      "makefile",
+	"GNUmakefile":   "makefile",
+	"Jenkinsfile":   "groovy",
+	"Vagrantfile":   "rb",
+	"Gemfile":       "rb",
+	"Rakefile":      "rb",
+	"Podfile":       "rb",
+	"Brewfile":      "rb",
+	"Guardfile":     "rb",
+	"Fastfile":      "rb",
+	"Capfile":       "rb",
+	"PKGBUILD":      "bash",
+	".bashrc":       "bash",
+	".bash_profile": "bash",
+	".bash_login":   "bash",
+	".bash_logout":  "bash",
+	".bash_aliases": "bash",
+	".profile":      "sh",
+	".zshrc":        "zsh",
+	".zshenv":       "zsh",
+	".zprofile":     "zsh",
+	".zlogin":       "zsh",
+	".zlogout":      "zsh",
+	".env":          "env",
+}
+
+// extensionLanguages maps extensions remapped in .shush.toml, or claimed by a
+// language defined there, to their languageMap key.
+var extensionLanguages = map[string]string{}
+
+// languageOverride is an [[overrides]] entry from .shush.toml.
+type languageOverride struct {
+	pattern *regexp.Regexp
+	dir     string
+	key     string
+}
+
+// languageOverrides are checked before anything else, in order.
+var languageOverrides []languageOverride
+
+// filenamePatterns are tried, in order, after filenameLanguages and before
+// the extension.
+var filenamePatterns = []struct {
+	pattern string
+	key     string
+}{
+	{"Dockerfile.*", "dockerfile"},
+	{"Containerfile.*", "dockerfile"},
+	{"Makefile.*", "makefile"},
+	{"*.mk", "makefile"},
+	{"*.mak", "makefile"},
+	{"Jenkinsfile.*", "groovy"},
+	{".env.*", "env"},
+}
+
+// namedLanguages are languageMap keys that are not file extensions. They
+// are only chosen by --lang, [extensions] or [[overrides]], as .m files may
+// be Objective-C or MATLAB.
+var namedLanguages = map[string]bool{
+	"objc":   true,
+	"nginx":  true,
+	"matlab": true,
+}
+
+// languageAliases maps interpreter names from shebangs and mode names from
+// modelines to languageMap keys. Names that already are keys need no entry.
+var languageAliases = map[string]string{
+	"python":       "py",
+	"pypy":         "py",
+	"ruby":         "rb",
+	"perl":         "pl",
+	"cperl":        "pl",
+	"node":         "js",
+	"nodejs":       "js",
+	"javascript":   "js",
+	"deno":         "ts",
+	"bun":          "ts",
+	"ts-node":      "ts",
+	"typescript":   "ts",
+	"postcss":      "css",
+	"dash":         "sh",
+	"ash":          "sh",
+	"ksh":          "sh",
+	"mksh":         "sh",
+	"shell":        "sh",
+	"shell-script": "sh",
+	"make":         "makefile",
+	"gmake":        "makefile",
+	"docker":       "dockerfile",
+	"rscript":      "r",
+	"pwsh":         "ps1",
+	"powershell":   "ps1",
+	"luajit":       "lua",
+	"runghc":       "hs",
+	"runhaskell":   "hs",
+	"haskell":      "hs",
+	"ocaml":        "ml",
+	"tuareg":       "ml",
+	"sbcl":         "lisp",
+	"clisp":        "lisp",
+	"guile":        "scm",
+	"scheme":       "scm",
+	"emacs-lisp":   "el",
+	"elisp":        "el",
+	"clojure":      "clj",
+	"bb":           "clj",
+	"kotlin":       "kts",
+	"c++":          "cpp",
+	"csharp":       "cs",
+	"rust":         "rs",
+	"golang":       "go",
+	"dosini":       "ini",
+	"mysql":        "sql",
+	"plsql":        "sql",
+	"octave":       "matlab",
+}
+
+var (
+	vimModeline   = regexp.MustCompile(`(?:^|\s)(?:vi|vim|ex)(?:[<=>]?\d+)?:(?:.*?[\s:])?(?:ft|filetype|syn|syntax)=([\w+.-]+)`)
+	emacsModeline = regexp.MustCompile(`-\*-(.*?)-\*-`)
+)
+
+// modelineLines is how many lines at each end of a file are searched for a
+// vim modeline, as vim does by default.
+const modelineLines = 5
+
+// detectLength is how much of each end of a file is read to find a shebang
+// or modeline.
+const detectLength = 4096
+
+// languageKey works out the languageMap key for filename. It tries, in
+// order, the overrides from .shush.toml, the exact file name, file name
+// patterns, the extension, a "#!" shebang, and a vim or emacs modeline.
+func languageKey(filename string) (string, error) {
+	for _, o := range languageOverrides {
+		if o.pattern.MatchString(overridePath(filename, o.dir)) {
+			return o.key, nil
+		}
+	}
+
+	base := filepath.Base(filename)
+	if key, ok := filenameLanguages[base]; ok {
+		return key, nil
+	}
+	for _, p := range filenamePatterns {
+		if ok, _ := filepath.Match(p.pattern, base); ok {
+			return p.key, nil
+		}
+	}
+
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(base)), ".")
+	if key, ok := extensionLanguages[ext]; ok {
+		return key, nil
+	}
+	if _, ok := languageMap[ext]; ok && !namedLanguages[ext] {
+		return ext, nil
+	}
+
+	if key := sniffLanguage(filename); key != "" {
+		return key, nil
+	}
+	if ext == "" {
+		return "", fmt.Errorf("no file extension, shebang or modeline found")
+	}
+	return "", fmt.Errorf("unsupported file extension: %s", ext)
+}
+
+// overridePath returns filename relative to dir, with forward slashes, or
+// as given when it lies outside dir.
+func overridePath(filename, dir string) string {
+	if abs, err := filepath.Abs(filename); err == nil {
+		if dir == "" {
+			dir, _ = os.Getwd()
+		}
+		if rel, err := filepath.Rel(dir, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			filename = rel
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(filename))
+}
+
+// globPattern compiles a glob into a regexp matched against a whole
+// slash-separated path. "*" and "?" stay within one path segment, "**"
+// spans any number of them, and a pattern without a slash matches the file
+// name in any directory.
+func globPattern(glob string) (*regexp.Regexp, error) {
+	if !strings.Contains(glob, "/") {
+		glob = "**/" + glob
+	}
+	glob = strings.TrimPrefix(glob, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated [ in %q", glob)
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// sniffLanguage looks for a shebang on the first line of filename and for a
+// modeline near either end.
+func sniffLanguage(filename string) string {
+	f, err := os.Open(filename)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	buf := make([]byte, detectLength)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return ""
+	}
+	head := string(buf[:n])
+
+	lines := strings.SplitN(head, "\n", modelineLines+1)
+	if strings.HasPrefix(head, "#!") {
+		if key := shebangLanguage(lines[0]); key != "" {
+			return key
+		}
+	}
+	for i, line := range lines[:min(len(lines), modelineLines)] {
+		if key := modelineLanguage(line, i < 2); key != "" {
+			return key
+		}
+	}
+
+	if info, err := f.Stat(); err == nil && info.Size() > int64(n) {
+		offset := info.Size() - detectLength
+		if offset < int64(n) {
+			offset = int64(n)
+		}
+		n, _ := f.ReadAt(buf, offset)
+		lines := strings.Split(strings.TrimRight(string(buf[:n]), "\n"), "\n")
+		for _, line := range lines[max(len(lines)-modelineLines, 0):] {
+			if key := modelineLanguage(line, false); key != "" {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+// shebangLanguage returns the language of the interpreter named by a "#!"
+// line, looking through env and its options.
+func shebangLanguage(line string) string {
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				interpreter = filepath.Base(field)
+				break
+			}
+		}
+	}
+	return aliasLanguage(interpreter)
+}
+
+// modelineLanguage returns the language set by a vim modeline in line, or by
+// an emacs "-*- mode -*-" line when emacs is set.
+func modelineLanguage(line string, emacs bool) string {
+	if m := vimModeline.FindStringSubmatch(line); m != nil {
+		return aliasLanguage(m[1])
+	}
+	if !emacs {
+		return ""
+	}
+	m := emacsModeline.FindStringSubmatch(line)
+	if m == nil {
+		return ""
+	}
+	if !strings.Contains(m[1], ":") {
+		return aliasLanguage(strings.TrimSpace(m[1]))
+	}
+	for _, setting := range strings.Split(m[1], ";") {
+		name, value, ok := strings.Cut(setting, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "mode") {
+			return aliasLanguage(strings.TrimSpace(value))
+		}
+	}
+	return ""
+}
+
+// aliasLanguage maps an interpreter or mode name to a languageMap key,
+// ignoring case and a version suffix as in "python3.12".
+func aliasLanguage(name string) string {
+	name = strings.ToLower(name)
+	for _, candidate := range []string{name, strings.TrimRight(name, "0123456789.-")} {
+		if key, ok := languageAliases[candidate]; ok {
+			return key
+		}
+		if _, ok := languageMap[candidate]; ok && candidate != "" {
+			return candidate
+		}
+	}
+	return ""
+}
@@ -0,0 +1,223 @@
+package processor
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/carlosarraes/shush/internal/config"
+)
+
+func TestLanguageKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected string
+	}{
+		{name: "dockerfile", file: "Dockerfile", expected: "dockerfile"},
+		{name: "dockerfile variant", file: "Dockerfile.dev", expected: "dockerfile"},
+		{name: "makefile", file: "Makefile", expected: "makefile"},
+		{name: "make include", file: "rules.mk", expected: "makefile"},
+		{name: "jenkinsfile", file: "Jenkinsfile", expected: "groovy"},
+		{name: "vagrantfile", file: "Vagrantfile", expected: "rb"},
+		{name: "gemfile", file: "Gemfile", expected: "rb"},
+		{name: "bashrc", file: ".bashrc", expected: "bash"},
+		{name: "dotenv", file: ".env", expected: "env"},
+		{name: "dotenv variant", file: ".env.local", expected: "env"},
+		{name: "extension", file: "main.go", expected: "go"},
+		{name: "language name is not an extension", file: "site.nginx", expected: ""},
+		{name: "objc is not an extension", file: "View.objc", expected: ""},
+		{name: "extension wins over content", file: "main.py", content: "#!/bin/bash\n", expected: "py"},
+		{name: "shebang", file: "deploy", content: "#!/bin/bash\necho hi\n", expected: "bash"},
+		{name: "env shebang with version", file: "tool", content: "#!/usr/bin/env python3.12\n", expected: "py"},
+		{name: "env shebang with options", file: "serve", content: "#!/usr/bin/env -S node --no-warnings\n", expected: "js"},
+		{name: "shebang on unsupported extension", file: "index.cgi", content: "#!/usr/bin/perl -w\n", expected: "pl"},
+		{name: "vim modeline at end", file: "build", content: "x = 1\n\n# vim: set ft=ruby sw=2 :\n", expected: "rb"},
+		{name: "vim modeline at top", file: "rules", content: "// vim:filetype=javascript\nrun();\n", expected: "js"},
+		{name: "emacs modeline", file: "setup", content: "# -*- mode: python; coding: utf-8 -*-\n", expected: "py"},
+		{name: "emacs mode only", file: "init", content: ";; -*- emacs-lisp -*-\n", expected: "el"},
+		{name: "unknown shebang", file: "run", content: "#!/usr/bin/unknown-interpreter\n", expected: ""},
+		{name: "plain file", file: "LICENSE", content: "MIT License\n", expected: ""},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(dir, tt.name, tt.file)
+			if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filename, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := languageKey(filename)
+			if tt.expected == "" {
+				if err == nil {
+					t.Errorf("languageKey() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.expected {
+				t.Errorf("languageKey() = %q, %v; want %q", got, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRegisterLanguages(t *testing.T) {
+	restoreRegistry(t)
+
+	cfg := config.Default()
+	_, err := toml.Decode(`
+[extensions]
+jsonc = "js"
+sky = "star"
+a1 = "zz"
+zz = "jsonc"
+
+[languages.starlark]
+extensions = ["star", ".BZL"]
+filenames = ["BUILD"]
+line_comments = ["#"]
+strings = ['"', '"""']
+
+[languages.tmpl]
+extensions = ["tmpl"]
+block_comment = ["{{/*", "*/}}"]
+
+[[overrides]]
+pattern = "ios/**/*.h"
+language = "Objective-C"
+
+[[overrides]]
+pattern = "*.nginx.conf"
+language = "nginx"
+
+[[overrides]]
+pattern = "lib/*.m"
+language = "starlark"
+`, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Dir = "/repo"
+	if err := RegisterLanguages(cfg); err != nil {
+		t.Fatalf("RegisterLanguages() error = %v", err)
+	}
+
+	for file, expected := range map[string]string{
+		"rules.star":     "starlark",
+		"defs.bzl":       "starlark",
+		"pkg/BUILD":      "starlark",
+		"x.sky":          "starlark",
+		"page.tmpl":      "tmpl",
+		"tsconfig.jsonc": "js",
+		"x.a1":           "js",
+		"x.zz":           "js",
+
+		"/repo/ios/App/View.h":         "objc",
+		"/repo/ios/View.h":             "objc",
+		"/repo/src/View.h":             "h",
+		"/repo/deploy/site.nginx.conf": "nginx",
+		"/repo/deploy/site.conf":       "conf",
+		"/repo/lib/plot.m":             "starlark",
+		"/repo/lib/sub/plot.m":         "",
+		"/elsewhere/ios/View.h":        "h",
+	} {
+		if expected == "" {
+			if got, err := languageKey(file); err == nil {
+				t.Errorf("languageKey(%q) = %q, want an error", file, got)
+			}
+			continue
+		}
+		if got, err := languageKey(file); err != nil || got != expected {
+			t.Errorf("languageKey(%q) = %q, %v; want %q", file, got, err, expected)
+		}
+	}
+	if got := GetLanguageName("rules.star"); got != "starlark" {
+		t.Errorf("GetLanguageName() = %q, want %q", got, "starlark")
+	}
+
+	language, err := DetectLanguage("rules.star")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := "x = \"\"\"\n# not a comment\n\"\"\" # comment\ny = \"#\""
+	result := (&Processor{}).stripComments(strings.Split(src, "\n"), language, config.Default(), nil)
+	expected := "x = \"\"\"\n# not a comment\n\"\"\"\ny = \"#\""
+	if got := strings.Join(result.output, "\n"); got != expected {
+		t.Errorf("stripComments() = %q, want %q", got, expected)
+	}
+}
+
+func TestRegisterLanguagesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+		err  string
+	}{
+		{name: "no markers", cfg: "[languages.x]\nextensions = [\"x\"]", err: "needs line_comments or block_comment"},
+		{name: "too many line markers", cfg: "[languages.x]\nline_comments = [\"#\", \";\", \"//\"]", err: "at most 2"},
+		{name: "half a block", cfg: "[languages.x]\nblock_comment = [\"/*\"]", err: "block_comment"},
+		{name: "nested without block", cfg: "[languages.x]\nline_comments = [\"#\"]\nnested = true", err: "nested"},
+		{name: "empty string delimiter", cfg: "[languages.x]\nline_comments = [\"#\"]\nstrings = [\"\"]", err: "empty delimiter"},
+		{name: "unknown remap target", cfg: "[extensions]\njsonc = \"nope\"", err: "unknown language"},
+		{name: "remap loop", cfg: "[extensions]\naa = \"bb\"\nbb = \"aa\"", err: "extensions.aa: remap loop"},
+		{name: "unknown override language", cfg: "[[overrides]]\npattern = \"*.h\"\nlanguage = \"nope\"", err: "overrides[0]: unknown language"},
+		{name: "bad override pattern", cfg: "[[overrides]]\npattern = \"[*.h\"\nlanguage = \"c\"", err: "unterminated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restoreRegistry(t)
+			cfg := config.Default()
+			if _, err := toml.Decode(tt.cfg, cfg); err != nil {
+				t.Fatal(err)
+			}
+			err := RegisterLanguages(cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("RegisterLanguages() error = %v, want one mentioning %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestLanguageByName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"py":          "#",
+		"python":      "#",
+		"Python":      "#",
+		"objc":        "//",
+		"Objective-C": "//",
+		"nginx":       "#",
+		"MATLAB":      "%",
+		"octave":      "%",
+		".lua":        "--",
+		"node":        "//",
+	} {
+		language, err := LanguageByName(name)
+		if err != nil || language.LineComment != expected {
+			t.Errorf("LanguageByName(%q) = %q, %v; want line comment %q", name, language.LineComment, err, expected)
+		}
+	}
+	if _, err := LanguageByName("klingon"); err == nil {
+		t.Errorf("LanguageByName(%q) should fail", "klingon")
+	}
+}
+
+// restoreRegistry puts the language registry back once the test is done.
+func restoreRegistry(t *testing.T) {
+	languages, names := maps.Clone(languageMap), maps.Clone(languageNames)
+	filenames, extensions := maps.Clone(filenameLanguages), maps.Clone(extensionLanguages)
+	overrides := languageOverrides
+	t.Cleanup(func() {
+		languageMap, languageNames = languages, names
+		filenameLanguages, extensionLanguages = filenames, extensions
+		languageOverrides = overrides
+	})
+}
@@ -0,0 +1,332 @@
+package processor
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+// headerLines is how far from the top of a file Header directives count.
+const headerLines = 2
+
+var (
+	shebang   = []types.Directive{{Tool: "shebang", Pattern: "#!", FileStart: true}}
+	modelines = []types.Directive{
+		{Tool: "emacs", Pattern: "-*-", Header: true, Anywhere: true},
+		{Tool: "vim", Pattern: "vim:", Anywhere: true},
+	}
+
+	pythonDirectives = []types.Directive{
+		{Tool: "python", Pattern: "coding:", Header: true, Anywhere: true},
+		{Tool: "python", Pattern: "coding=", Header: true, Anywhere: true},
+		{Tool: "flake8", Pattern: "noqa"},
+		{Tool: "ruff", Pattern: "ruff:"},
+		{Tool: "coverage", Pattern: "pragma: no cover"},
+		{Tool: "coverage", Pattern: "pragma: no branch"},
+		{Tool: "mypy", Pattern: "type: ignore"},
+		{Tool: "mypy", Pattern: "mypy:"},
+		{Tool: "pyright", Pattern: "pyright:"},
+		{Tool: "pylint", Pattern: "pylint:"},
+		{Tool: "black", Pattern: "fmt: off"},
+		{Tool: "black", Pattern: "fmt: on"},
+		{Tool: "black", Pattern: "fmt: skip"},
+		{Tool: "isort", Pattern: "isort:"},
+		{Tool: "bandit", Pattern: "nosec"},
+	}
+	rubyDirectives = []types.Directive{
+		{Tool: "ruby", Pattern: "frozen_string_literal:", Header: true, Anywhere: true},
+		{Tool: "ruby", Pattern: "encoding:", Header: true, Anywhere: true},
+		{Tool: "ruby", Pattern: "warn_indent:", Header: true, Anywhere: true},
+		{Tool: "ruby", Pattern: "shareable_constant_value:", Header: true, Anywhere: true},
+		{Tool: "rubocop", Pattern: "rubocop:"},
+		{Tool: "sorbet", Pattern: "typed:", Header: true},
+		{Tool: "simplecov", Pattern: ":nocov:"},
+	}
+	perlDirectives = []types.Directive{
+		{Tool: "perlcritic", Pattern: "no critic"},
+		{Tool: "perltidy", Pattern: "perltidy"},
+	}
+	shellDirectives = []types.Directive{
+		{Tool: "shellcheck", Pattern: "shellcheck "},
+	}
+	jsDirectives = []types.Directive{
+		{Tool: "typescript", Pattern: "@ts-check"},
+		{Tool: "typescript", Pattern: "@ts-nocheck"},
+		{Tool: "typescript", Pattern: "@ts-ignore"},
+		{Tool: "typescript", Pattern: "@ts-expect-error"},
+		{Tool: "typescript", Pattern: "<reference "},
+		{Tool: "typescript", Pattern: "<amd-module "},
+		{Tool: "eslint", Pattern: "eslint-disable"},
+		{Tool: "eslint", Pattern: "eslint-enable"},
+		{Tool: "eslint", Pattern: "eslint-env"},
+		{Tool: "eslint", Pattern: "/* eslint "},
+		{Tool: "eslint", Pattern: "/* global "},
+		{Tool: "jshint", Pattern: "jshint"},
+		{Tool: "tslint", Pattern: "tslint:"},
+		{Tool: "biome", Pattern: "biome-ignore"},
+		{Tool: "deno", Pattern: "deno-lint-ignore"},
+		{Tool: "prettier", Pattern: "prettier-ignore"},
+		{Tool: "istanbul", Pattern: "istanbul ignore"},
+		{Tool: "c8", Pattern: "c8 ignore"},
+		{Tool: "v8", Pattern: "v8 ignore"},
+		{Tool: "flow", Pattern: "@flow", Anywhere: true},
+		{Tool: "jsx", Pattern: "@jsx"},
+		{Tool: "webpack", Pattern: "webpackChunkName", Anywhere: true},
+		{Tool: "vite", Pattern: "@vite-ignore"},
+		{Tool: "minifier", Pattern: "#__PURE__"},
+		{Tool: "minifier", Pattern: "@__PURE__"},
+		{Tool: "minifier", Pattern: "@license", Anywhere: true},
+		{Tool: "minifier", Pattern: "@preserve", Anywhere: true},
+		{Tool: "sourcemap", Pattern: "sourceMappingURL="},
+		{Tool: "sourcemap", Pattern: "sourceURL="},
+	}
+	goDirectives = []types.Directive{
+		{Tool: "golangci-lint", Pattern: "//nolint"},
+		{Tool: "staticcheck", Pattern: "//lint:"},
+		{Tool: "gosec", Pattern: "#nosec", Anywhere: true},
+		{Tool: "go", Pattern: "Code generated"},
+	}
+	cDirectives = []types.Directive{
+		{Tool: "clang-tidy", Pattern: "NOLINT", Anywhere: true},
+		{Tool: "clang-format", Pattern: "clang-format off"},
+		{Tool: "clang-format", Pattern: "clang-format on"},
+		{Tool: "cppcheck", Pattern: "cppcheck-suppress"},
+		{Tool: "coverity", Pattern: "coverity["},
+		{Tool: "lcov", Pattern: "LCOV_EXCL"},
+		{Tool: "gcc", Pattern: "fallthrough"},
+		{Tool: "gcc", Pattern: "FALLTHROUGH"},
+		{Tool: "gcc", Pattern: "fall through"},
+	}
+	sonarDirectives = []types.Directive{
+		{Tool: "sonar", Pattern: "NOSONAR", Anywhere: true},
+	}
+	jvmDirectives = []types.Directive{
+		{Tool: "checkstyle", Pattern: "CHECKSTYLE:"},
+		{Tool: "pmd", Pattern: "NOPMD"},
+		{Tool: "intellij", Pattern: "noinspection"},
+		{Tool: "intellij", Pattern: "@formatter:"},
+	}
+	kotlinDirectives = []types.Directive{
+		{Tool: "ktlint", Pattern: "ktlint-disable"},
+		{Tool: "ktlint", Pattern: "ktlint-enable"},
+	}
+	scalaDirectives = []types.Directive{
+		{Tool: "scalastyle", Pattern: "scalastyle:"},
+		{Tool: "scalafmt", Pattern: "format: off"},
+		{Tool: "scalafmt", Pattern: "format: on"},
+	}
+	csharpDirectives = []types.Directive{
+		{Tool: "resharper", Pattern: "ReSharper disable"},
+		{Tool: "resharper", Pattern: "ReSharper restore"},
+		{Tool: "msbuild", Pattern: "<auto-generated"},
+	}
+	rustDirectives = []types.Directive{
+		{Tool: "clippy", Pattern: "SAFETY:"},
+	}
+	swiftDirectives = []types.Directive{
+		{Tool: "swiftlint", Pattern: "swiftlint:"},
+		{Tool: "swift-format", Pattern: "swift-format-ignore"},
+	}
+	dartDirectives = []types.Directive{
+		{Tool: "dart", Pattern: "ignore:"},
+		{Tool: "dart", Pattern: "ignore_for_file:"},
+		{Tool: "dart", Pattern: "@dart="},
+	}
+	haskellDirectives = []types.Directive{
+		{Tool: "ghc", Pattern: "{-#"},
+		{Tool: "hlint", Pattern: "HLINT"},
+	}
+	elispDirectives = []types.Directive{
+		{Tool: "emacs", Pattern: "###autoload"},
+		{Tool: "emacs", Pattern: "Local Variables:"},
+	}
+	phpDirectives = []types.Directive{
+		{Tool: "phpcs", Pattern: "phpcs:"},
+		{Tool: "phpstan", Pattern: "@phpstan-"},
+		{Tool: "psalm", Pattern: "@psalm-"},
+		{Tool: "phpunit", Pattern: "@codeCoverageIgnore"},
+	}
+	cssDirectives = []types.Directive{
+		{Tool: "stylelint", Pattern: "stylelint-disable"},
+		{Tool: "stylelint", Pattern: "stylelint-enable"},
+		{Tool: "prettier", Pattern: "prettier-ignore"},
+		{Tool: "minifier", Pattern: "/*!"},
+		{Tool: "sourcemap", Pattern: "sourceMappingURL="},
+	}
+	markupDirectives = []types.Directive{
+		{Tool: "ie", Pattern: "[if "},
+		{Tool: "ie", Pattern: "<![endif]"},
+		{Tool: "ssi", Pattern: "<!--#"},
+		{Tool: "prettier", Pattern: "prettier-ignore"},
+		{Tool: "cdata", Pattern: "<![CDATA["},
+		{Tool: "cdata", Pattern: "]]>"},
+	}
+	vueDirectives = []types.Directive{
+		{Tool: "vue", Pattern: "@vue-ignore"},
+		{Tool: "vue", Pattern: "@vue-skip"},
+		{Tool: "vue", Pattern: "@vue-expect-error"},
+		{Tool: "vue", Pattern: "@vue-generic"},
+	}
+	svelteDirectives = []types.Directive{
+		{Tool: "svelte", Pattern: "svelte-ignore"},
+	}
+	yamlDirectives = []types.Directive{
+		{Tool: "yamllint", Pattern: "yamllint "},
+		{Tool: "yaml-language-server", Pattern: "yaml-language-server:"},
+	}
+	tomlDirectives = []types.Directive{
+		{Tool: "taplo", Pattern: "#:schema", Header: true},
+	}
+	sqlDirectives = []types.Directive{
+		{Tool: "sqlfluff", Pattern: "noqa"},
+		{Tool: "liquibase", Pattern: "liquibase formatted", Header: true},
+		{Tool: "liquibase", Pattern: "--changeset "},
+		{Tool: "liquibase", Pattern: "--rollback "},
+	}
+	dockerDirectives = []types.Directive{
+		{Tool: "docker", Pattern: "syntax="},
+		{Tool: "docker", Pattern: "escape="},
+		{Tool: "docker", Pattern: "check="},
+		{Tool: "hadolint", Pattern: "hadolint "},
+	}
+	luaDirectives = []types.Directive{
+		{Tool: "luacheck", Pattern: "luacheck:"},
+		{Tool: "lua-language-server", Pattern: "@diagnostic"},
+		{Tool: "stylua", Pattern: "stylua: ignore"},
+	}
+	rDirectives = []types.Directive{
+		{Tool: "lintr", Pattern: "nolint"},
+		{Tool: "styler", Pattern: "styler: off"},
+		{Tool: "styler", Pattern: "styler: on"},
+	}
+	matlabDirectives = []types.Directive{
+		{Tool: "mlint", Pattern: "%#ok"},
+		{Tool: "matlab", Pattern: "%#codegen"},
+		{Tool: "matlab", Pattern: "%#function"},
+	}
+	powershellDirectives = []types.Directive{
+		{Tool: "powershell", Pattern: "#Requires"},
+	}
+)
+
+// directiveMap holds the built-in directive registry, keyed like languageMap.
+var directiveMap = map[string][]types.Directive{
+	"lua":  luaDirectives,
+	"py":   directiveSet(shebang, modelines, pythonDirectives),
+	"sh":   directiveSet(shebang, modelines, shellDirectives),
+	"bash": directiveSet(shebang, modelines, shellDirectives),
+	"zsh":  directiveSet(shebang, modelines, shellDirectives),
+	"fish": directiveSet(shebang, modelines),
+	"ps1":  powershellDirectives,
+	"r":    directiveSet(shebang, rDirectives),
+
+	"js":  directiveSet(jsDirectives, sonarDirectives),
+	"ts":  directiveSet(jsDirectives, sonarDirectives),
+	"jsx": directiveSet(jsDirectives, sonarDirectives),
+	"tsx": directiveSet(jsDirectives, sonarDirectives),
+
+	"go":    directiveSet(goDirectives, sonarDirectives),
+	"c":     directiveSet(modelines, cDirectives, sonarDirectives),
+	"cpp":   directiveSet(modelines, cDirectives, sonarDirectives),
+	"cc":    directiveSet(modelines, cDirectives, sonarDirectives),
+	"cxx":   directiveSet(modelines, cDirectives, sonarDirectives),
+	"h":     directiveSet(modelines, cDirectives, sonarDirectives),
+	"hpp":   directiveSet(modelines, cDirectives, sonarDirectives),
+	"objc":  directiveSet(modelines, cDirectives, sonarDirectives),
+	"mm":    directiveSet(modelines, cDirectives, sonarDirectives),
+	"java":  directiveSet(jvmDirectives, sonarDirectives),
+	"cs":    directiveSet(csharpDirectives, sonarDirectives),
+	"rs":    rustDirectives,
+	"swift": swiftDirectives,
+	"kt":    directiveSet(jvmDirectives, kotlinDirectives, sonarDirectives),
+	"kts":   directiveSet(jvmDirectives, kotlinDirectives, sonarDirectives),
+	"dart":  dartDirectives,
+	"scala": directiveSet(scalaDirectives, sonarDirectives),
+	"hs":    haskellDirectives,
+	"el":    directiveSet(modelines, elispDirectives),
+	"php":   directiveSet(shebang, phpDirectives, sonarDirectives),
+
+	"css":  cssDirectives,
+	"scss": cssDirectives,
+	"sass": cssDirectives,
+	"less": cssDirectives,
+	"html": directiveSet(markupDirectives, jsDirectives, cssDirectives),
+	"htm":  directiveSet(markupDirectives, jsDirectives, cssDirectives),
+	"xml":  markupDirectives,
+	"svg":  directiveSet(markupDirectives, jsDirectives, cssDirectives),
+
+	"vue":    directiveSet(markupDirectives, jsDirectives, cssDirectives, vueDirectives, sonarDirectives),
+	"svelte": directiveSet(markupDirectives, jsDirectives, cssDirectives, svelteDirectives, sonarDirectives),
+	"astro":  directiveSet(markupDirectives, jsDirectives, cssDirectives, sonarDirectives),
+
+	"rb":         directiveSet(shebang, modelines, rubyDirectives),
+	"pl":         directiveSet(shebang, modelines, perlDirectives),
+	"yml":        yamlDirectives,
+	"yaml":       yamlDirectives,
+	"toml":       tomlDirectives,
+	"sql":        sqlDirectives,
+	"matlab":     matlabDirectives,
+	"dockerfile": dockerDirectives,
+}
+
+// directiveSet joins directive lists, dropping entries already listed.
+func directiveSet(sets ...[]types.Directive) []types.Directive {
+	var all []types.Directive
+	for _, set := range sets {
+		for _, d := range set {
+			if !slices.Contains(all, d) {
+				all = append(all, d)
+			}
+		}
+	}
+	return all
+}
+
+func DirectiveRegistry() map[string][]types.Directive {
+	return directiveMap
+}
+
+// directiveTool returns the tool that reads comment c, or "" when c is not a
+// protected directive. line is the number of lines before src in its file.
+func directiveTool(c comment, src string, line int, language types.Language, cfg *config.Config) string {
+	text := src[c.start:c.end]
+	markers := []string{language.LineComment, language.AlternateLineComment}
+	for _, d := range language.Directives {
+		if d.FileStart && (line != 0 || c.start != 0) {
+			continue
+		}
+		if d.Header && line+strings.Count(src[:c.start], "\n") >= headerLines {
+			continue
+		}
+		if (d.Anywhere && strings.Contains(text, d.Pattern) || opensBody(text, d.Pattern, markers)) && cfg.ProtectsDirective(d.Tool) {
+			return d.Tool
+		}
+	}
+	return ""
+}
+
+// opensBody reports whether pattern opens the body of the comment text, of
+// one of its lines, or of the part after another comment marker, as "noqa"
+// does in "# type: ignore  # noqa".
+func opensBody(text, pattern string, markers []string) bool {
+	for from := 0; ; {
+		i := strings.Index(text[from:], pattern)
+		if i == -1 {
+			return false
+		}
+		i += from
+
+		start := strings.LastIndexByte(text[:i], '\n') + 1
+		for _, marker := range markers {
+			if j := strings.LastIndex(text[:i], marker); marker != "" && j >= start {
+				start = j + len(marker)
+			}
+		}
+		if atBodyStart(text[start:], i-start) {
+			return true
+		}
+		from = i + 1
+	}
+}
@@ -0,0 +1,122 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+func TestStripCommentsDirectives(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		strip    []string
+		src      string
+		expected string
+	}{
+		{
+			name:     "python header and suppressions",
+			file:     "x.py",
+			src:      "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\n# comment\nimport os  # noqa: F401\nx = 1  # pragma: no cover",
+			expected: "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\nimport os  # noqa: F401\nx = 1  # pragma: no cover",
+		},
+		{
+			name:     "magic comments only in the header",
+			file:     "x.rb",
+			src:      "#!/usr/bin/env ruby\n# frozen_string_literal: true\n# helpers\nx = 1\n# decoding: strip the header\n# encoding: utf-8",
+			expected: "#!/usr/bin/env ruby\n# frozen_string_literal: true\nx = 1",
+		},
+		{
+			name:     "python coding comment after the header",
+			file:     "x.py",
+			src:      "import os\n\n# decoding: strip the header\nx = 1",
+			expected: "import os\n\nx = 1",
+		},
+		{
+			name:     "shebang only on the first line",
+			file:     "x.sh",
+			src:      "echo hi\n#!/bin/sh",
+			expected: "echo hi",
+		},
+		{
+			name:     "typescript directives",
+			file:     "x.ts",
+			src:      "/// <reference path=\"a.d.ts\" />\n// @ts-check\n// comment\n/* istanbul ignore next */\nfoo(); // eslint-disable-line",
+			expected: "/// <reference path=\"a.d.ts\" />\n// @ts-check\n/* istanbul ignore next */\nfoo(); // eslint-disable-line",
+		},
+		{
+			name:     "go nolint",
+			file:     "x.go",
+			src:      "package p\n\nvar x = 1 //nolint:gochecknoglobals\n// comment",
+			expected: "package p\n\nvar x = 1 //nolint:gochecknoglobals",
+		},
+		{
+			name:     "directives open the comment body",
+			file:     "x.dart",
+			src:      "// ignore: unused_local_variable\nvar x = 1; // we can ignore: this\n// ignore_for_file: type=lint",
+			expected: "// ignore: unused_local_variable\nvar x = 1;\n// ignore_for_file: type=lint",
+		},
+		{
+			name:     "directive after another comment marker",
+			file:     "x.py",
+			src:      "x = f()  # type: ignore  # noqa: E501\ny = g()  # see the noqa docs",
+			expected: "x = f()  # type: ignore  # noqa: E501\ny = g()",
+		},
+		{
+			name:     "fallthrough comments and anywhere markers",
+			file:     "x.c",
+			src:      "case 1: /* fallthrough */\ncase 2: // we must not fallthrough here\ncase 3: // checked by hand NOSONAR",
+			expected: "case 1: /* fallthrough */\ncase 2:\ncase 3: // checked by hand NOSONAR",
+		},
+		{
+			name:     "dockerfile parser directive",
+			file:     "Dockerfile",
+			src:      "# syntax=docker/dockerfile:1\n# keep the syntax= line first\nFROM scratch",
+			expected: "# syntax=docker/dockerfile:1\nFROM scratch",
+		},
+		{
+			name:     "stripped tool",
+			file:     "x.rb",
+			strip:    []string{"rubocop"},
+			src:      "# frozen_string_literal: true\nx = 1 # rubocop:disable Style/Foo",
+			expected: "# frozen_string_literal: true\nx = 1",
+		},
+		{
+			name:     "all directives stripped",
+			file:     "x.py",
+			strip:    []string{"*"},
+			src:      "import os  # noqa",
+			expected: "import os",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			language, err := DetectLanguage(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := config.Default()
+			cfg.Preserve = nil
+			cfg.StripDirectives = tt.strip
+
+			got := (&Processor{}).stripString(tt.src, language, cfg, nil)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDirectiveRegistryHasNoDuplicates(t *testing.T) {
+	for key, directives := range directiveMap {
+		seen := map[types.Directive]bool{}
+		for _, d := range directives {
+			if seen[d] {
+				t.Errorf("%s lists %s %q twice", key, d.Tool, d.Pattern)
+			}
+			seen[d] = true
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package processor
+
+import (
+	"strings"
+)
+
+// dockerfileLexer follows the Dockerfile rule that "#" only starts a
+// comment at the beginning of a line, after optional whitespace. Anywhere
+// else it belongs to the instruction, as in ENV COLOR=#fff. Heredoc bodies
+// are kept whole.
+type dockerfileLexer struct{}
+
+func (l *dockerfileLexer) scan(src string) []comment {
+	var comments []comment
+
+	for i := 0; i < len(src); {
+		end := strings.IndexByte(src[i:], '\n')
+		if end == -1 {
+			end = len(src)
+		} else {
+			end += i
+		}
+
+		line := src[i:end]
+		var pending []heredoc
+		if trimmed := strings.TrimLeft(line, " \t"); strings.HasPrefix(trimmed, "#") {
+			comments = append(comments, comment{start: end - len(trimmed), end: end, kind: lineComment})
+		} else {
+			pending = dockerfileHeredocs(line)
+		}
+
+		i = end + 1
+		if len(pending) > 0 && i < len(src) {
+			i = skipHeredocBodies(src, i, pending)
+		}
+	}
+
+	return comments
+}
+
+// dockerfileHeredocs returns the heredocs opened on an instruction line, as
+// in RUN <<EOF or COPY <<-"EOT" /app/.
+func dockerfileHeredocs(line string) []heredoc {
+	var docs []heredoc
+	for i := 0; ; {
+		j := strings.Index(line[i:], "<<")
+		if j == -1 {
+			return docs
+		}
+		doc, end, ok := parseHeredoc(line, i+j+2)
+		if ok {
+			docs = append(docs, doc)
+		}
+		i = max(end, i+j+2)
+	}
+}
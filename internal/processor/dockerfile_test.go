@@ -0,0 +1,40 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+)
+
+func TestStripCommentsDockerfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "hash inside instructions",
+			src:      "# base image\nFROM alpine\nENV COLOR=#fff\nRUN curl http://x/#frag # not a comment either",
+			expected: "FROM alpine\nENV COLOR=#fff\nRUN curl http://x/#frag # not a comment either",
+		},
+		{
+			name:     "indented comment inside a continuation",
+			src:      "RUN apk add \\\n    # tools\n    curl",
+			expected: "RUN apk add \\\n    curl",
+		},
+		{
+			name:     "heredoc bodies",
+			src:      "RUN <<EOF\n#!/bin/sh\n# setup\necho hi\nEOF\n# done",
+			expected: "RUN <<EOF\n#!/bin/sh\n# setup\necho hi\nEOF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Processor{}).stripString(tt.src, languageMap["dockerfile"], config.Default(), nil)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
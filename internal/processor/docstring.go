@@ -0,0 +1,128 @@
+package processor
+
+import (
+	"strings"
+)
+
+// statement is a logical Python statement: the offsets of its first and last
+// code bytes, and whether it consists of a single string literal.
+type statement struct {
+	start   int
+	end     int
+	literal bool
+}
+
+// pythonStatements splits src into logical statements, treating the literal
+// and comment spans already found by the lexer as atomic. Newlines inside
+// brackets or after a backslash continue the current statement.
+func pythonStatements(src string, literals, comments []comment) []statement {
+	var statements []statement
+	var current *statement
+
+	depth := 0
+	li, ci := 0, 0
+
+	for i := 0; i < len(src); {
+		if ci < len(comments) && comments[ci].start == i {
+			i = comments[ci].end
+			ci++
+			continue
+		}
+
+		if li < len(literals) && literals[li].start == i {
+			lit := literals[li]
+			li++
+
+			switch {
+			case current == nil:
+				statements = append(statements, statement{start: i, literal: true})
+				current = &statements[len(statements)-1]
+			case current.end == i-1 && isDocstringPrefix(src[current.start:i]):
+				current.literal = true
+			default:
+				current.literal = false
+			}
+			current.end = lit.end - 1
+			i = lit.end
+			continue
+		}
+
+		switch c := src[i]; c {
+		case ' ', '\t', '\r', '\f':
+		case '\n':
+			if depth == 0 {
+				current = nil
+			}
+		case '\\':
+			if i+1 < len(src) && src[i+1] == '\n' {
+				i += 2
+				continue
+			}
+			fallthrough
+		default:
+			if current == nil {
+				statements = append(statements, statement{start: i})
+				current = &statements[len(statements)-1]
+			} else if current.literal && !isDocstringPrefix(src[current.start:i+1]) {
+				current.literal = false
+			}
+			switch c {
+			case '(', '[', '{':
+				depth++
+			case ')', ']', '}':
+				depth = max(0, depth-1)
+			}
+			current.end = i
+		}
+		i++
+	}
+
+	return statements
+}
+
+// pythonDocstrings picks the string literals that are docstrings: a string
+// expression forming the first statement of the module or of a def/class
+// body.
+func pythonDocstrings(src string, statements []statement) []comment {
+	var docs []comment
+	for k, st := range statements {
+		if st.literal && (k == 0 || opensDefinition(src, statements[k-1])) {
+			docs = append(docs, comment{start: st.start, end: st.end + 1, kind: docstringComment})
+		}
+	}
+	return docs
+}
+
+// pythonBlockHeaders returns the statements that end in a colon and so open
+// an indented block on the following lines.
+func pythonBlockHeaders(src string, statements []statement) []statement {
+	var headers []statement
+	for _, st := range statements {
+		if src[st.end] == ':' {
+			headers = append(headers, st)
+		}
+	}
+	return headers
+}
+
+func isDocstringPrefix(s string) bool {
+	switch s {
+	case "r", "R", "u", "U":
+		return true
+	}
+	return false
+}
+
+func opensDefinition(src string, st statement) bool {
+	if src[st.end] != ':' {
+		return false
+	}
+
+	header := src[st.start:st.end]
+	for _, keyword := range []string{"def", "async def", "class"} {
+		if strings.HasPrefix(header, keyword+" ") || strings.HasPrefix(header, keyword+"\t") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+func TestStripCommentsDocstrings(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		cli      types.CLI
+		expected string
+	}{
+		{
+			name:     "docstrings kept by default",
+			src:      "def f():\n    \"\"\"Doc.\"\"\"\n    return 1",
+			expected: "def f():\n    \"\"\"Doc.\"\"\"\n    return 1",
+		},
+		{
+			name: "module, class and function docstrings",
+			src: `"""Module doc."""
+import os
+
+
+class A:
+    """Class doc.
+
+    More detail.
+    """
+
+    def f(self, a,
+          b):
+        r"""Method doc."""
+        x = """not a docstring"""
+        return x`,
+			cli: types.CLI{Docstrings: true},
+			expected: `import os
+
+
+class A:
+
+    def f(self, a,
+          b):
+        x = """not a docstring"""
+        return x`,
+		},
+		{
+			name:     "docstring-only body gets pass",
+			src:      "class Error(Exception):\n    \"\"\"Raised on failure.\"\"\"\n\nx = 1",
+			cli:      types.CLI{Docstrings: true},
+			expected: "class Error(Exception):\n    pass\n\nx = 1",
+		},
+		{
+			name:     "preserve patterns apply to docstrings",
+			src:      "def f():\n    \"\"\"TODO: document\"\"\"\n    return 1",
+			cli:      types.CLI{Docstrings: true},
+			expected: "def f():\n    \"\"\"TODO: document\"\"\"\n    return 1",
+		},
+		{
+			name:     "string after if is not a docstring",
+			src:      "if x:\n    \"\"\"expression\"\"\"",
+			cli:      types.CLI{Docstrings: true},
+			expected: "if x:\n    \"\"\"expression\"\"\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Processor{cli: tt.cli}).stripString(tt.src, languageMap["py"], config.Default(), nil)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	encodingUTF8    = "UTF-8"
+	encodingUTF16LE = "UTF-16LE"
+	encodingUTF16BE = "UTF-16BE"
+	encodingLatin1  = "Latin-1"
+)
+
+const utf8BOM = "\xef\xbb\xbf"
+
+// sniffLength is how far into a file a NUL byte marks it as binary, as git
+// does.
+const sniffLength = 8000
+
+var errBinaryFile = errors.New("binary file")
+
+// decodeSource works out how data is encoded and returns it as UTF-8 text.
+// A byte order mark selects UTF-8 or UTF-16. Without one, a NUL byte near the
+// start marks the file as binary, and data that is not valid UTF-8 is read as
+// Latin-1. Data that would not encode back to the same bytes is binary too.
+func decodeSource(data []byte) (text, encoding string, bom bool, err error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xff\xfe\x00\x00")), bytes.HasPrefix(data, []byte("\x00\x00\xfe\xff")):
+		return "", "", false, fmt.Errorf("%w (UTF-32 is not supported)", errBinaryFile)
+	case bytes.HasPrefix(data, []byte(utf8BOM)):
+		return string(data[len(utf8BOM):]), encodingUTF8, true, nil
+	case bytes.HasPrefix(data, []byte("\xff\xfe")):
+		text, encoding = decodeUTF16(data[2:], binary.LittleEndian), encodingUTF16LE
+	case bytes.HasPrefix(data, []byte("\xfe\xff")):
+		text, encoding = decodeUTF16(data[2:], binary.BigEndian), encodingUTF16BE
+	case bytes.IndexByte(data[:min(len(data), sniffLength)], 0) != -1:
+		return "", "", false, errBinaryFile
+	case utf8.Valid(data):
+		return string(data), encodingUTF8, false, nil
+	default:
+		text, encoding = decodeLatin1(string(data)), encodingLatin1
+	}
+
+	bom = encoding != encodingLatin1
+	if encoded, err := encodeSource(text, encoding, bom); err != nil || !bytes.Equal(encoded, data) {
+		return "", "", false, fmt.Errorf("%w (not valid %s)", errBinaryFile, encoding)
+	}
+	return text, encoding, bom, nil
+}
+
+// encodeSource turns text back into bytes in the given encoding, writing a
+// byte order mark first when bom is set.
+func encodeSource(text, encoding string, bom bool) ([]byte, error) {
+	var out []byte
+	switch encoding {
+	case encodingUTF16LE, encodingUTF16BE:
+		var order binary.AppendByteOrder = binary.LittleEndian
+		if encoding == encodingUTF16BE {
+			order = binary.BigEndian
+		}
+		if bom {
+			out = order.AppendUint16(out, 0xfeff)
+		}
+		for _, unit := range utf16.Encode([]rune(text)) {
+			out = order.AppendUint16(out, unit)
+		}
+	case encodingLatin1:
+		for _, r := range text {
+			if r > 0xff {
+				return nil, fmt.Errorf("%q cannot be written as Latin-1", r)
+			}
+			out = append(out, byte(r))
+		}
+	default:
+		if bom {
+			out = append(out, utf8BOM...)
+		}
+		out = append(out, text...)
+	}
+	return out, nil
+}
+
+func decodeLatin1(data string) string {
+	runes := make([]rune, len(data))
+	for i := 0; i < len(data); i++ {
+		runes[i] = rune(data[i])
+	}
+	return string(runes)
+}
+
+func decodeUTF16(data []byte, order binary.ByteOrder) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	text := string(utf16.Decode(units))
+	if len(data)%2 != 0 {
+		text += string(utf8.RuneError)
+	}
+	return text
+}
@@ -1,14 +1,12 @@
 package processor
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/carlosarraes/shush/internal/config"
 	"github.com/carlosarraes/shush/internal/git"
-	"github.com/carlosarraes/shush/internal/types"
 	"github.com/fatih/color"
 )
 
@@ -130,164 +128,29 @@ func (p *Processor) processFileWithLineRanges(filename string, lineRanges []git.
 		}
 	}
 
-	file, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+	src, err := readSource(filename, cfg.MaxFileSize)
+	if p.skipped(filename, err) {
+		return nil
 	}
-
-	if err := scanner.Err(); err != nil {
+	if err != nil {
 		return err
 	}
 
-	if p.cli.Backup {
-		if err := p.createBackup(filename); err != nil {
-			return fmt.Errorf("failed to create backup: %v", err)
-		}
-		if p.cli.Verbose {
-			fmt.Printf("✓ Backup created: %s.bak\n", filename)
-		}
-	}
-
-	processEntireFile := len(lineRanges) == 0
-	modified := false
-	var processedLines []string
-
-	for i, line := range lines {
-		lineNum := i + 1
-		shouldProcess := processEntireFile || git.IsInLineRanges(lineNum, lineRanges)
-
-		if shouldProcess {
-			newLine := p.removeCommentsFromLine(line, language, cfg)
-			if newLine != line {
-				modified = true
-
-				if newLine != "" {
-					processedLines = append(processedLines, newLine)
-				}
-
-			} else {
-				processedLines = append(processedLines, line)
-			}
-		} else {
-			processedLines = append(processedLines, line)
-		}
-	}
-
-	if modified {
-		outFile, err := os.Create(filename)
-		if err != nil {
-			return err
-		}
-		defer outFile.Close()
-
-		for _, line := range processedLines {
-			if _, err := fmt.Fprintln(outFile, line); err != nil {
-				return err
-			}
-		}
-
-		if p.cli.Verbose {
-			fmt.Printf("✓ Comments removed from %s\n", filename)
-		}
-	} else if p.cli.Verbose {
-		fmt.Printf("✓ No changes made to %s\n", filename)
-	}
-
-	return nil
+	result := p.stripComments(src.lines, language, cfg, lineRangeScope(lineRanges))
+	return p.finishFile(filename, result, func() error {
+		return writeSource(filename, src, result)
+	})
 }
 
-func (p *Processor) removeCommentsFromLine(line string, language types.Language, cfg *config.Config) string {
-	originalLine := line
-	result := line
-	hasChanges := false
-
-	if !p.cli.Block {
-		if language.LineComment != "" {
-			if idx := p.findCommentIndex(result, language.LineComment); idx != -1 {
-				comment := strings.TrimSpace(result[idx:])
-				if cfg.ShouldPreserveComment(comment) {
-					return originalLine
-				}
-				result = result[:idx]
-				hasChanges = true
-			}
-		}
-
-		if language.AlternateLineComment != "" && !hasChanges {
-			if idx := p.findCommentIndex(result, language.AlternateLineComment); idx != -1 {
-				comment := strings.TrimSpace(result[idx:])
-				if cfg.ShouldPreserveComment(comment) {
-					return originalLine
-				}
-				result = result[:idx]
-				hasChanges = true
-			}
-		}
-	}
-
-	if !p.cli.Inline && language.BlockComment != nil {
-
-		startComment := language.BlockComment.Start
-		endComment := language.BlockComment.End
-
-		for {
-			startIdx := p.findCommentIndex(result, startComment)
-			if startIdx == -1 {
-				break
-			}
-
-			endIdx := p.findCommentIndex(result[startIdx:], endComment)
-			if endIdx == -1 {
-
-				comment := strings.TrimSpace(result[startIdx:])
-				if cfg.ShouldPreserveComment(comment) {
-					return originalLine
-				}
-
-				result = result[:startIdx]
-				hasChanges = true
-				break
-			}
-
-			blockComment := strings.TrimSpace(result[startIdx : startIdx+endIdx+len(endComment)])
-			if cfg.ShouldPreserveComment(blockComment) {
-				return originalLine
-			}
-
-			endIdx += startIdx + len(endComment)
-			result = result[:startIdx] + result[endIdx:]
-			hasChanges = true
-		}
+// lineRangeScope limits processing to the given git line ranges. Untracked
+// files have no ranges and are processed in full.
+func lineRangeScope(lineRanges []git.LineRange) func(lineNum int) bool {
+	if len(lineRanges) == 0 {
+		return nil
 	}
-
-	if hasChanges {
-		result = strings.TrimRight(result, " \t")
-
-		if strings.TrimSpace(result) == "" {
-			if p.cli.PreserveLines {
-				leadingWhitespace := ""
-				for _, char := range originalLine {
-					if char == ' ' || char == '\t' {
-						leadingWhitespace += string(char)
-					} else {
-						break
-					}
-				}
-				return leadingWhitespace
-			} else {
-				return ""
-			}
-		}
+	return func(lineNum int) bool {
+		return git.IsInLineRanges(lineNum, lineRanges)
 	}
-
-	return result
 }
 
 func (p *Processor) showGitPreviewWithTotals(filename string, lineRanges []git.LineRange, totals *GitTotals) error {
@@ -306,11 +169,14 @@ func (p *Processor) showGitPreviewWithTotals(filename string, lineRanges []git.L
 		contextLines = p.cli.ContextLines
 	}
 
-	file, err := os.Open(filename)
+	src, err := readSource(filename, cfg.MaxFileSize)
+	if p.skipped(filename, err) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	lines := src.lines
 
 	red := color.New(color.FgRed, color.CrossedOut)
 	green := color.New(color.FgGreen)
@@ -327,48 +193,17 @@ func (p *Processor) showGitPreviewWithTotals(filename string, lineRanges []git.L
 	}
 	fmt.Println()
 
-	scanner := bufio.NewScanner(file)
-	var lines []string
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	keptCount := 0
-	changedCount := 0
-	preservedCount := 0
-	processEntireFile := len(lineRanges) == 0
+	result := p.stripComments(lines, language, cfg, lineRangeScope(lineRanges))
+	changes := result.changes
 
-	var changes []changeInfo
+	changedCount := result.changed
+	keptCount := result.kept
+	preservedCount := result.preserved
 
-	for i, line := range lines {
-		lineNum := i + 1
-		shouldProcess := processEntireFile || git.IsInLineRanges(lineNum, lineRanges)
-
-		if shouldProcess {
-			newLine := p.removeCommentsFromLine(line, language, cfg)
-			if newLine != line {
-				changedCount++
-				if newLine == "" {
-					changes = append(changes, changeInfo{lineNum, line, newLine, "removed"})
-				} else {
-					changes = append(changes, changeInfo{lineNum, line, newLine, "modified"})
-				}
-			} else {
-				hasComment := p.lineHasComment(line, language)
-				if hasComment {
-					preservedCount++
-					changes = append(changes, changeInfo{lineNum, line, line, "preserved"})
-				} else {
-					keptCount++
-				}
-			}
-		} else {
-			keptCount++
-		}
+	if result.ignored {
+		fmt.Printf("%s File skipped by shush:ignore-file\n", gray.Sprint("→"))
+		fmt.Println()
+		return nil
 	}
 
 	if len(changes) == 0 {
@@ -381,17 +216,7 @@ func (p *Processor) showGitPreviewWithTotals(filename string, lineRanges []git.L
 		p.displayChangesWithContext(lines, changes, contextLines, red, green, blue, gray, dimGray)
 	} else {
 		for _, change := range changes {
-			lineNumStr := gray.Sprintf("%4d", change.lineNum)
-			switch change.changeType {
-			case "removed":
-				fmt.Printf("%s %s %s\n", lineNumStr, red.Sprint("-"), red.Sprint(change.oldLine))
-			case "modified":
-				fmt.Printf("%s %s %s\n", lineNumStr, red.Sprint("~"), red.Sprint(change.oldLine))
-				fmt.Printf("%s %s %s\n", lineNumStr, green.Sprint("+"), green.Sprint(change.newLine))
-			case "preserved":
-				cyan := color.New(color.FgCyan)
-				fmt.Printf("%s %s %s\n", lineNumStr, cyan.Sprint("P"), change.oldLine)
-			}
+			printChange(change, red, green, gray)
 		}
 	}
 
@@ -402,6 +227,9 @@ func (p *Processor) showGitPreviewWithTotals(filename string, lineRanges []git.L
 		cyan := color.New(color.FgCyan)
 		fmt.Printf("%s %d comments would be preserved\n", cyan.Sprint("P"), preservedCount)
 	}
+	if result.guarded > 0 {
+		fmt.Printf("%s %d empty blocks would be guarded\n", yellow.Sprint("!"), result.guarded)
+	}
 	fmt.Println()
 
 	totals.TotalChanged += changedCount
@@ -411,61 +239,12 @@ func (p *Processor) showGitPreviewWithTotals(filename string, lineRanges []git.L
 	return nil
 }
 
-func (p *Processor) findCommentIndex(line, commentMarker string) int {
-	inString := false
-	var stringChar byte
-
-	for i := 0; i < len(line); i++ {
-		char := line[i]
-
-		if !inString && (char == '"' || char == '\'' || char == '`') {
-			inString = true
-			stringChar = char
-			continue
-		}
-
-		if inString && char == stringChar {
-
-			if i > 0 && line[i-1] != '\\' {
-				inString = false
-			}
-			continue
-		}
-
-		if !inString {
-			if i+len(commentMarker) <= len(line) && line[i:i+len(commentMarker)] == commentMarker {
-				return i
-			}
-		}
-	}
-
-	return -1
-}
-
-// lineHasComment checks if a line contains comments
-func (p *Processor) lineHasComment(line string, language types.Language) bool {
-	if language.LineComment != "" && p.findCommentIndex(line, language.LineComment) != -1 {
-		return true
-	}
-
-	if language.AlternateLineComment != "" && p.findCommentIndex(line, language.AlternateLineComment) != -1 {
-		return true
-	}
-
-	if language.BlockComment != nil {
-		if p.findCommentIndex(line, language.BlockComment.Start) != -1 || p.findCommentIndex(line, language.BlockComment.End) != -1 {
-			return true
-		}
-	}
-
-	return false
-}
-
 type changeInfo struct {
 	lineNum    int
 	oldLine    string
 	newLine    string
 	changeType string
+	note       string
 }
 
 func (p *Processor) displayChangesWithContext(lines []string, changes []changeInfo, contextLines int, red, green, blue, gray, dimGray *color.Color) {
@@ -473,9 +252,12 @@ func (p *Processor) displayChangesWithContext(lines []string, changes []changeIn
 		return
 	}
 
-	cyan := color.New(color.FgCyan)
 	totalLines := len(lines)
 	displayedLines := make(map[int]bool)
+	changeAt := make(map[int]changeInfo, len(changes))
+	for _, change := range changes {
+		changeAt[change.lineNum] = change
+	}
 
 	for i, change := range changes {
 		startLine := max(1, change.lineNum-contextLines)
@@ -496,26 +278,36 @@ func (p *Processor) displayChangesWithContext(lines []string, changes []changeIn
 			}
 			displayedLines[lineNum] = true
 
-			lineNumStr := gray.Sprintf("%4d", lineNum)
-			line := lines[lineNum-1]
-
-			if lineNum == change.lineNum {
-				switch change.changeType {
-				case "removed":
-					fmt.Printf("%s %s %s\n", lineNumStr, red.Sprint("-"), red.Sprint(line))
-				case "modified":
-					fmt.Printf("%s %s %s\n", lineNumStr, red.Sprint("~"), red.Sprint(line))
-					fmt.Printf("%s %s %s\n", lineNumStr, green.Sprint("+"), green.Sprint(change.newLine))
-				case "preserved":
-					fmt.Printf("%s %s %s\n", lineNumStr, cyan.Sprint("P"), line)
-				}
+			if lineChange, ok := changeAt[lineNum]; ok {
+				printChange(lineChange, red, green, gray)
 			} else {
+				lineNumStr := gray.Sprintf("%4d", lineNum)
+				line := lines[lineNum-1]
 				fmt.Printf("%s %s %s\n", lineNumStr, dimGray.Sprint(" "), dimGray.Sprint(line))
 			}
 		}
 	}
 }
 
+func printChange(change changeInfo, red, green, gray *color.Color) {
+	cyan := color.New(color.FgCyan)
+	lineNumStr := gray.Sprintf("%4d", change.lineNum)
+	note := ""
+	if change.note != "" {
+		note = " " + gray.Sprintf("(%s)", change.note)
+	}
+
+	switch change.changeType {
+	case "removed":
+		fmt.Printf("%s %s %s%s\n", lineNumStr, red.Sprint("-"), red.Sprint(change.oldLine), note)
+	case "modified":
+		fmt.Printf("%s %s %s\n", lineNumStr, red.Sprint("~"), red.Sprint(change.oldLine))
+		fmt.Printf("%s %s %s%s\n", lineNumStr, green.Sprint("+"), green.Sprint(change.newLine), note)
+	case "preserved":
+		fmt.Printf("%s %s %s%s\n", lineNumStr, cyan.Sprint("P"), change.oldLine, note)
+	}
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
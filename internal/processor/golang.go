@@ -0,0 +1,273 @@
+package processor
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// goLexer finds comments with the standard library scanner, so raw strings,
+// runes and struct tags need no heuristics. Comments the toolchain reads are
+// marked to be kept, and the parsed file tells which comments document
+// exported identifiers.
+type goLexer struct{}
+
+func (l *goLexer) scan(src string) []comment {
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, "", src, parser.ParseComments)
+	preamble := cgoPreamble(fset, f)
+	docs := declarationDocs(fset, f)
+	exported := exportedDocs(fset, f)
+
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, scanner.ScanComments)
+
+	var comments []comment
+	for {
+		pos, tok, _ := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.COMMENT {
+			continue
+		}
+
+		c := comment{start: file.Offset(pos), kind: lineComment}
+		if strings.HasPrefix(src[c.start:], "/*") {
+			c.kind = blockComment
+			c.end = len(src)
+			if end := strings.Index(src[c.start+2:], "*/"); end != -1 {
+				c.end = c.start + 2 + end + 2
+			}
+		} else {
+			c.end = len(src)
+			if end := strings.IndexByte(src[c.start:], '\n'); end != -1 {
+				c.end = c.start + end
+			}
+		}
+
+		c.keep = goDirective(src[c.start:c.end])
+		if c.keep == "" && preamble[c.start] {
+			c.keep = "cgo preamble"
+		}
+		c.doc = docs[c.start]
+		c.exportedDoc = exported[c.start]
+		comments = append(comments, c)
+	}
+
+	return comments
+}
+
+func goDirective(text string) string {
+	switch {
+	case strings.HasPrefix(text, "//go:build"), strings.HasPrefix(text, "// +build"):
+		return "build constraint"
+	case strings.HasPrefix(text, "//go:"):
+		return "go directive"
+	case strings.HasPrefix(text, "//export "):
+		return "cgo export"
+	case strings.HasPrefix(text, "//line "), strings.HasPrefix(text, "/*line "):
+		return "line directive"
+	}
+	return ""
+}
+
+// cgoPreamble returns the offsets of the comments forming the doc of an
+// import "C" declaration. cgo compiles that comment as C, so it is code.
+func cgoPreamble(fset *token.FileSet, f *ast.File) map[int]bool {
+	if f == nil {
+		return nil
+	}
+
+	preamble := make(map[int]bool)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			if path, err := strconv.Unquote(imp.Path.Value); err != nil || path != "C" {
+				continue
+			}
+			doc := imp.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			if doc == nil {
+				continue
+			}
+			markGroup(preamble, fset, doc)
+		}
+	}
+	return preamble
+}
+
+// declarationDocs returns the offsets of every doc comment in the file,
+// whatever it documents.
+func declarationDocs(fset *token.FileSet, f *ast.File) map[int]bool {
+	if f == nil {
+		return nil
+	}
+
+	docs := make(map[int]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.File:
+			markGroup(docs, fset, n.Doc)
+		case *ast.FuncDecl:
+			markGroup(docs, fset, n.Doc)
+		case *ast.GenDecl:
+			markGroup(docs, fset, n.Doc)
+		case *ast.Field:
+			markGroup(docs, fset, n.Doc)
+		case ast.Spec:
+			markGroup(docs, fset, specDoc(n))
+		}
+		return true
+	})
+	return docs
+}
+
+// exportedDocs returns the offsets of the comments documenting the package
+// clause, the exported package-level declarations and the exported fields
+// and methods of exported types, as go doc shows them.
+func exportedDocs(fset *token.FileSet, f *ast.File) map[int]bool {
+	if f == nil {
+		return nil
+	}
+
+	docs := make(map[int]bool)
+	markGroup(docs, fset, f.Doc)
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Name.IsExported() && (d.Recv == nil || receiverExported(d.Recv)) {
+				markGroup(docs, fset, d.Doc)
+			}
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+			exported := false
+			for _, spec := range d.Specs {
+				if specExported(spec) {
+					exported = true
+					markGroup(docs, fset, specDoc(spec))
+					if t, ok := spec.(*ast.TypeSpec); ok {
+						markFieldDocs(docs, fset, t.Type)
+					}
+				}
+			}
+			if exported {
+				markGroup(docs, fset, d.Doc)
+			}
+		}
+	}
+	return docs
+}
+
+func markGroup(offsets map[int]bool, fset *token.FileSet, group *ast.CommentGroup) {
+	if group == nil {
+		return
+	}
+	for _, c := range group.List {
+		offsets[fset.PositionFor(c.Pos(), false).Offset] = true
+	}
+}
+
+// markFieldDocs marks the docs of the exported fields of a struct type, or
+// the exported methods of an interface type, and of the struct and interface
+// types nested in them.
+func markFieldDocs(offsets map[int]bool, fset *token.FileSet, typ ast.Expr) {
+	var fields *ast.FieldList
+	switch t := typ.(type) {
+	case *ast.StructType:
+		fields = t.Fields
+	case *ast.InterfaceType:
+		fields = t.Methods
+	default:
+		return
+	}
+
+	for _, field := range fields.List {
+		if fieldExported(field) {
+			markGroup(offsets, fset, field.Doc)
+			markFieldDocs(offsets, fset, field.Type)
+		}
+	}
+}
+
+// fieldExported reports whether a struct field or interface method is
+// exported. An embedded field is exported when its type name is.
+func fieldExported(field *ast.Field) bool {
+	if len(field.Names) == 0 {
+		return typeExported(field.Type)
+	}
+	for _, name := range field.Names {
+		if name.IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+func receiverExported(recv *ast.FieldList) bool {
+	if len(recv.List) == 0 {
+		return false
+	}
+	return typeExported(recv.List[0].Type)
+}
+
+// typeExported reports whether the type named by typ, behind any pointer,
+// type arguments or parentheses, is exported.
+func typeExported(typ ast.Expr) bool {
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		case *ast.ParenExpr:
+			typ = t.X
+		case *ast.Ident:
+			return t.IsExported()
+		case *ast.SelectorExpr:
+			return t.Sel.IsExported()
+		default:
+			return false
+		}
+	}
+}
+
+func specExported(spec ast.Spec) bool {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return s.Name.IsExported()
+	case *ast.ValueSpec:
+		for _, name := range s.Names {
+			if name.IsExported() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func specDoc(spec ast.Spec) *ast.CommentGroup {
+	switch s := spec.(type) {
+	case *ast.ImportSpec:
+		return s.Doc
+	case *ast.TypeSpec:
+		return s.Doc
+	case *ast.ValueSpec:
+		return s.Doc
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+)
+
+func TestStripCommentsGo(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "raw strings, runes and struct tags",
+			src:      "var s = `// not` + \"/* x */\" // comment\nvar r = '/' /* c */\ntype T struct {\n\tA int `json:\"a\"` // comment\n}",
+			expected: "var s = `// not` + \"/* x */\"\nvar r = '/'\ntype T struct {\n\tA int `json:\"a\"`\n}",
+		},
+		{
+			name:     "toolchain directives kept",
+			src:      "//go:build linux\n// +build linux\n\n// Package p does things.\npackage p\n\n//go:generate stringer -type=T\n//export Add\n//line foo.go:10\nfunc Add() {}",
+			expected: "//go:build linux\n// +build linux\n\npackage p\n\n//go:generate stringer -type=T\n//export Add\n//line foo.go:10\nfunc Add() {}",
+		},
+		{
+			name:     "cgo preamble kept",
+			src:      "package p\n\n// #include <stdio.h>\n// int add(int a, int b) { return a + b; }\nimport \"C\"\n\n// comment\nimport \"fmt\"",
+			expected: "package p\n\n// #include <stdio.h>\n// int add(int a, int b) { return a + b; }\nimport \"C\"\n\nimport \"fmt\"",
+		},
+		{
+			name:     "detached comment above import C removed",
+			src:      "package p\n\n// comment\n\nimport \"C\"",
+			expected: "package p\n\n\nimport \"C\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Processor{}).stripString(tt.src, languageMap["go"], config.Default(), nil)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStripCommentsGoExportedDocs(t *testing.T) {
+	src := strings.Join([]string{
+		"// Package p does things.",
+		"package p",
+		"",
+		"// Exported is documented.",
+		"func Exported() {",
+		"\t// body comment",
+		"}",
+		"",
+		"// helper is not exported.",
+		"func helper() {}",
+		"",
+		"// Value is documented.",
+		"var Value = 1 // trailing",
+		"",
+		"// T is a type.",
+		"type T struct{}",
+		"",
+		"// Method is on an exported type.",
+		"func (t *T) Method() {}",
+		"",
+		"// method is unexported.",
+		"func (t T) method() {}",
+		"",
+		"// S has fields.",
+		"type S struct {",
+		"\t// Name is exported.",
+		"\tName string",
+		"\t// id is not.",
+		"\tid int",
+		"}",
+		"",
+		"type hidden struct {",
+		"\t// Field of an unexported type.",
+		"\tField int",
+		"}",
+	}, "\n")
+	expected := strings.Join([]string{
+		"// Package p does things.",
+		"package p",
+		"",
+		"// Exported is documented.",
+		"func Exported() {",
+		"}",
+		"",
+		"func helper() {}",
+		"",
+		"// Value is documented.",
+		"var Value = 1",
+		"",
+		"// T is a type.",
+		"type T struct{}",
+		"",
+		"// Method is on an exported type.",
+		"func (t *T) Method() {}",
+		"",
+		"func (t T) method() {}",
+		"",
+		"// S has fields.",
+		"type S struct {",
+		"\t// Name is exported.",
+		"\tName string",
+		"\tid int",
+		"}",
+		"",
+		"type hidden struct {",
+		"\tField int",
+		"}",
+	}, "\n")
+
+	cfg := config.Default()
+	cfg.KeepExportedDocs = true
+
+	p := &Processor{}
+	result := p.stripComments(strings.Split(src, "\n"), languageMap["go"], cfg, nil)
+	if got := strings.Join(result.output, "\n"); got != expected {
+		t.Errorf("stripComments() = %q, want %q", got, expected)
+	}
+
+	for _, change := range result.changes {
+		if change.changeType == "preserved" && change.note != "exported doc" {
+			t.Errorf("line %d preserved with note %q, want %q", change.lineNum, change.note, "exported doc")
+		}
+	}
+	if result.preserved != 7 {
+		t.Errorf("preserved = %d, want 7", result.preserved)
+	}
+
+	result = p.stripComments(strings.Split(src, "\n"), languageMap["go"], config.Default(), nil)
+	if result.preserved != 0 {
+		t.Errorf("preserved without keep_exported_docs = %d, want 0", result.preserved)
+	}
+}
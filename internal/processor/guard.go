@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"strings"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+// blockHeaderLexer is implemented by lexers that know which statements open
+// an indented block, so that blocks emptied by removal can be repaired.
+type blockHeaderLexer interface {
+	blockHeaders() []statement
+}
+
+// guardEmptyBlocks finds indented blocks whose every line was removed and
+// keeps them valid. By default the first removed line becomes the language's
+// empty statement; with empty_blocks = "keep" a removed docstring is put back
+// instead when there is one. Inner blocks are handled before outer ones so a
+// repaired inner block counts as a statement of its parent. It returns the
+// number of blocks repaired.
+func (p *Processor) guardEmptyBlocks(lines []string, states []lineState, starts []int, comments []comment, headers []statement, language types.Language, cfg *config.Config) int {
+	guarded := 0
+
+	for h := len(headers) - 1; h >= 0; h-- {
+		header := headers[h]
+		indent := leadingWhitespace(lines[lineIndex(starts, header.start)])
+
+		var removed []int
+		empty := true
+		for i := lineIndex(starts, header.end) + 1; i < len(states); i++ {
+			if states[i].changeType == "removed" {
+				removed = append(removed, i)
+				continue
+			}
+			if strings.TrimSpace(states[i].text) == "" {
+				continue
+			}
+			empty = len(leadingWhitespace(states[i].text)) <= len(indent)
+			break
+		}
+
+		if !empty || len(removed) == 0 {
+			continue
+		}
+		guarded++
+
+		if cfg.EmptyBlocks == "keep" {
+			if k := lastDocstring(states, removed, comments); k != -1 {
+				c := comments[k]
+				for i := lineIndex(starts, c.start); i <= lineIndex(starts, c.end-1); i++ {
+					states[i] = lineState{text: lines[i], changeType: "preserved", note: "empty block: kept docstring", comment: -1}
+				}
+				continue
+			}
+		}
+
+		first := removed[0]
+		bodyIndent := leadingWhitespace(lines[first])
+		if len(bodyIndent) <= len(indent) {
+			bodyIndent = indent + indentUnit(indent)
+		}
+		states[first].text = bodyIndent + language.EmptyBlock
+		states[first].changeType = "modified"
+		states[first].note = "empty block: inserted " + language.EmptyBlock
+	}
+
+	return guarded
+}
+
+func lastDocstring(states []lineState, removed []int, comments []comment) int {
+	for j := len(removed) - 1; j >= 0; j-- {
+		if k := states[removed[j]].comment; k != -1 && comments[k].kind == docstringComment {
+			return k
+		}
+	}
+	return -1
+}
+
+func indentUnit(indent string) string {
+	if strings.HasPrefix(indent, "\t") {
+		return "\t"
+	}
+	return "    "
+}
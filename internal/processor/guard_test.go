@@ -0,0 +1,66 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+func TestStripCommentsEmptyBlockGuard(t *testing.T) {
+	keep := config.Default()
+	keep.EmptyBlocks = "keep"
+
+	tests := []struct {
+		name     string
+		src      string
+		cli      types.CLI
+		cfg      *config.Config
+		expected string
+		guarded  int
+	}{
+		{
+			name:     "comment-only body gets pass",
+			src:      "if x:\n    # nothing yet\nelse:\n    y = 1",
+			cfg:      config.Default(),
+			expected: "if x:\n    pass\nelse:\n    y = 1",
+			guarded:  1,
+		},
+		{
+			name:     "nested empty blocks repaired innermost first",
+			src:      "def f():\n    for a in b:\n        # skip\n# trailing\nz = 1",
+			cfg:      config.Default(),
+			expected: "def f():\n    for a in b:\n        pass\nz = 1",
+			guarded:  1,
+		},
+		{
+			name:     "body with remaining code untouched",
+			src:      "def f():\n    # comment\n    return 1",
+			cfg:      config.Default(),
+			expected: "def f():\n    return 1",
+		},
+		{
+			name:     "keep mode restores the docstring",
+			src:      "class E(Exception):\n    \"\"\"Doc.\"\"\"\n    # note",
+			cli:      types.CLI{Docstrings: true},
+			cfg:      keep,
+			expected: "class E(Exception):\n    \"\"\"Doc.\"\"\"",
+			guarded:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Processor{cli: tt.cli}
+			result := p.stripComments(strings.Split(tt.src, "\n"), languageMap["py"], tt.cfg, nil)
+			got := strings.Join(result.output, "\n")
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+			if result.guarded != tt.guarded {
+				t.Errorf("guarded = %d, want %d", result.guarded, tt.guarded)
+			}
+		})
+	}
+}
@@ -3,157 +3,387 @@ package processor
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/carlosarraes/shush/internal/ignore"
 	"github.com/carlosarraes/shush/internal/types"
 )
 
+var (
+	cStrings = []types.StringLiteral{
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "u8'", End: "'", Escape: '\\', Form: types.CharLiteral},
+		{Start: "u'", End: "'", Escape: '\\', Form: types.CharLiteral},
+		{Start: "U'", End: "'", Escape: '\\', Form: types.CharLiteral},
+		{Start: "L'", End: "'", Escape: '\\', Form: types.CharLiteral},
+		{Start: "'", End: "'", Escape: '\\', Form: types.CharLiteral},
+	}
+	cppStrings = []types.StringLiteral{
+		{Start: `u8R"`, Form: types.CppRawString},
+		{Start: `uR"`, Form: types.CppRawString},
+		{Start: `UR"`, Form: types.CppRawString},
+		{Start: `LR"`, Form: types.CppRawString},
+		{Start: `R"`, Form: types.CppRawString},
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "u8'", End: "'", Escape: '\\', Form: types.CharLiteral},
+		{Start: "u'", End: "'", Escape: '\\', Form: types.CharLiteral},
+		{Start: "U'", End: "'", Escape: '\\', Form: types.CharLiteral},
+		{Start: "L'", End: "'", Escape: '\\', Form: types.CharLiteral},
+		{Start: "'", End: "'", Escape: '\\', Form: types.CharLiteral},
+	}
+	csharpStrings = []types.StringLiteral{
+		{Start: `"""`, Form: types.CSharpRawString},
+		{Start: `@"`, End: `"`, Multiline: true, Form: types.VerbatimString},
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "'", End: "'", Escape: '\\'},
+	}
+	goStrings = []types.StringLiteral{
+		{Start: "`", End: "`", Multiline: true},
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "'", End: "'", Escape: '\\'},
+	}
+	jsStrings = []types.StringLiteral{
+		{Start: "`", End: "`", Escape: '\\', Multiline: true, Form: types.TemplateString},
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "'", End: "'", Escape: '\\'},
+	}
+	pythonStrings = []types.StringLiteral{
+		{Start: `"""`, End: `"""`, Escape: '\\', Multiline: true},
+		{Start: "'''", End: "'''", Escape: '\\', Multiline: true},
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "'", End: "'", Escape: '\\'},
+	}
+	rustStrings = []types.StringLiteral{
+		{Start: "br", Form: types.RustRawString},
+		{Start: "r", Form: types.RustRawString},
+		{Start: `"`, End: `"`, Escape: '\\', Multiline: true},
+		{Start: "b'", End: "'", Escape: '\\', Form: types.CharLiteral},
+		{Start: "'", End: "'", Escape: '\\', Form: types.CharLiteral},
+	}
+	swiftStrings = []types.StringLiteral{
+		{Start: "#", Form: types.RustRawString},
+		{Start: `"""`, End: `"""`, Escape: '\\', Multiline: true},
+		{Start: `"`, End: `"`, Escape: '\\'},
+	}
+	jvmStrings = []types.StringLiteral{
+		{Start: `"""`, End: `"""`, Multiline: true},
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "'", End: "'", Escape: '\\', Form: types.CharLiteral},
+	}
+	groovyStrings = []types.StringLiteral{
+		{Start: `"""`, End: `"""`, Escape: '\\', Multiline: true},
+		{Start: "'''", End: "'''", Escape: '\\', Multiline: true},
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "'", End: "'", Escape: '\\'},
+	}
+	javaStrings = []types.StringLiteral{
+		{Start: `"""`, End: `"""`, Escape: '\\', Multiline: true},
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "'", End: "'", Escape: '\\'},
+	}
+	dartStrings = []types.StringLiteral{
+		{Start: `r"""`, End: `"""`, Multiline: true},
+		{Start: "r'''", End: "'''", Multiline: true},
+		{Start: `"""`, End: `"""`, Escape: '\\', Multiline: true},
+		{Start: "'''", End: "'''", Escape: '\\', Multiline: true},
+		{Start: `r"`, End: `"`},
+		{Start: "r'", End: "'"},
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "'", End: "'", Escape: '\\'},
+	}
+	scriptStrings = []types.StringLiteral{
+		{Start: `"`, End: `"`, Escape: '\\', Multiline: true},
+		{Start: "'", End: "'", Escape: '\\', Multiline: true},
+		{Start: "`", End: "`", Escape: '\\', Multiline: true},
+	}
+	sqlStrings = []types.StringLiteral{
+		{Start: "'", End: "'", Multiline: true},
+		{Start: `"`, End: `"`},
+		{Start: "`", End: "`"},
+	}
+	haskellStrings = []types.StringLiteral{
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "'", End: "'", Escape: '\\', Form: types.CharLiteral},
+	}
+	cDocs       = []string{"///", "//!", "/**", "/*!"}
+	slashDocs   = []string{"///", "/**"}
+	javadocDocs = []string{"/**"}
+	haskellDocs = []string{"-- |", "-- ^", "{-|"}
+	ocamlDocs   = []string{"(**"}
+	luaDocs     = []string{"---"}
+	rDocs       = []string{"#'"}
+
+	luaStrings = []types.StringLiteral{
+		{Start: "[", Form: types.LongBracketString},
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "'", End: "'", Escape: '\\'},
+	}
+	matlabStrings = []types.StringLiteral{
+		{Start: `"`, End: `"`, Form: types.VerbatimString},
+		{Start: "'", End: "'", Form: types.VerbatimString},
+	}
+	ocamlStrings = []types.StringLiteral{
+		{Start: "{|", End: "|}", Multiline: true},
+		{Start: `"`, End: `"`, Escape: '\\', Multiline: true},
+		{Start: "'", End: "'", Escape: '\\', Form: types.CharLiteral},
+	}
+	lispStrings = []types.StringLiteral{
+		{Start: `#\"`},
+		{Start: `#\;`},
+		{Start: `"`, End: `"`, Escape: '\\', Multiline: true},
+	}
+	elispStrings = []types.StringLiteral{
+		{Start: `?\\`},
+		{Start: `?\"`},
+		{Start: `?"`},
+		{Start: `?;`},
+		{Start: `"`, End: `"`, Escape: '\\', Multiline: true},
+	}
+	clojureStrings = []types.StringLiteral{
+		{Start: `\\`},
+		{Start: `\"`},
+		{Start: `\;`},
+		{Start: `"`, End: `"`, Escape: '\\', Multiline: true},
+	}
+	yamlStrings = []types.StringLiteral{
+		{Start: `"`, End: `"`, Escape: '\\', Multiline: true, WordStart: true},
+		{Start: "'", End: "'", Multiline: true, WordStart: true},
+	}
+	nginxStrings = []types.StringLiteral{
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "'", End: "'", Escape: '\\'},
+	}
+	tomlStrings = []types.StringLiteral{
+		{Start: `"""`, End: `"""`, Escape: '\\', Multiline: true},
+		{Start: "'''", End: "'''", Multiline: true},
+		{Start: `"`, End: `"`, Escape: '\\'},
+		{Start: "'", End: "'"},
+	}
+)
+
 var languageMap = map[string]types.Language{
-	"lua":  {LineComment: "--"},
-	"py":   {LineComment: "#"},
-	"sh":   {LineComment: "#"},
-	"bash": {LineComment: "#"},
-	"zsh":  {LineComment: "#"},
-	"fish": {LineComment: "#"},
+	"lua":  {LineComment: "--", BlockComment: &types.BlockComment{Start: "--", End: "]]", LongBracket: true}, Strings: luaStrings, DocComments: luaDocs},
+	"py":   {LineComment: "#", Strings: pythonStrings, Docstrings: true, EmptyBlock: "pass"},
+	"sh":   {LineComment: "#", Lexer: types.ShellLexer},
+	"bash": {LineComment: "#", Lexer: types.ShellLexer},
+	"zsh":  {LineComment: "#", Lexer: types.ShellLexer},
+	"fish": {LineComment: "#", Lexer: types.ShellLexer},
 	"ps1":  {LineComment: "#"},
-	"r":    {LineComment: "#"},
-
-	"js":    {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"ts":    {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"jsx":   {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"tsx":   {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"go":    {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"c":     {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"cpp":   {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"cc":    {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"cxx":   {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"h":     {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"hpp":   {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"java":  {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"cs":    {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"rs":    {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"swift": {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"kt":    {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"kts":   {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"dart":  {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"scala": {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
-	"php":   {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
+	"r":    {LineComment: "#", DocComments: rDocs},
+
+	"js":     {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: jsStrings, Regex: types.JSRegex, DocComments: javadocDocs},
+	"ts":     {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: jsStrings, Regex: types.JSRegex, DocComments: javadocDocs},
+	"jsx":    {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: jsStrings, Regex: types.JSRegex, DocComments: javadocDocs},
+	"tsx":    {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: jsStrings, Regex: types.JSRegex, DocComments: javadocDocs},
+	"go":     {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: goStrings, Lexer: types.GoLexer},
+	"c":      {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: cStrings, DocComments: cDocs},
+	"cpp":    {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: cppStrings, DocComments: cDocs},
+	"cc":     {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: cppStrings, DocComments: cDocs},
+	"cxx":    {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: cppStrings, DocComments: cDocs},
+	"h":      {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: cppStrings, DocComments: cDocs},
+	"hpp":    {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: cppStrings, DocComments: cDocs},
+	"objc":   {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: cStrings, DocComments: cDocs},
+	"mm":     {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: cppStrings, DocComments: cDocs},
+	"java":   {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: javaStrings, DocComments: javadocDocs},
+	"cs":     {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: csharpStrings, DocComments: slashDocs},
+	"rs":     {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/", Nested: true}, Strings: rustStrings, DocComments: cDocs},
+	"swift":  {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/", Nested: true}, Strings: swiftStrings, DocComments: slashDocs},
+	"kt":     {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/", Nested: true}, Strings: jvmStrings, DocComments: javadocDocs},
+	"kts":    {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/", Nested: true}, Strings: jvmStrings, DocComments: javadocDocs},
+	"dart":   {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/", Nested: true}, Strings: dartStrings, DocComments: slashDocs},
+	"scala":  {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/", Nested: true}, Strings: jvmStrings, DocComments: javadocDocs},
+	"groovy": {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: groovyStrings, DocComments: javadocDocs},
+	"gradle": {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: groovyStrings, DocComments: javadocDocs},
+	"hs":     {LineComment: "--", BlockComment: &types.BlockComment{Start: "{-", End: "-}", Nested: true}, Strings: haskellStrings, DocComments: haskellDocs},
+	"ml":     {BlockComment: &types.BlockComment{Start: "(*", End: "*)", Nested: true}, Strings: ocamlStrings, DocComments: ocamlDocs},
+	"mli":    {BlockComment: &types.BlockComment{Start: "(*", End: "*)", Nested: true}, Strings: ocamlStrings, DocComments: ocamlDocs},
+
+	"lisp": {LineComment: ";", BlockComment: &types.BlockComment{Start: "#|", End: "|#", Nested: true}, Strings: lispStrings},
+	"scm":  {LineComment: ";", BlockComment: &types.BlockComment{Start: "#|", End: "|#", Nested: true}, Strings: lispStrings},
+	"el":   {LineComment: ";", Strings: elispStrings},
+	"clj":  {LineComment: ";", Strings: clojureStrings},
+	"cljs": {LineComment: ";", Strings: clojureStrings},
+	"cljc": {LineComment: ";", Strings: clojureStrings},
+
+	"php": {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: scriptStrings, DocComments: javadocDocs},
 
 	"css":  {BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
 	"scss": {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
 	"sass": {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
 	"less": {LineComment: "//", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
 
-	"html": {BlockComment: &types.BlockComment{Start: "<!--", End: "-->"}},
-	"htm":  {BlockComment: &types.BlockComment{Start: "<!--", End: "-->"}},
-	"xml":  {BlockComment: &types.BlockComment{Start: "<!--", End: "-->"}},
-	"svg":  {BlockComment: &types.BlockComment{Start: "<!--", End: "-->"}},
+	"html": {BlockComment: &types.BlockComment{Start: "<!--", End: "-->"}, Lexer: types.MarkupLexer},
+	"htm":  {BlockComment: &types.BlockComment{Start: "<!--", End: "-->"}, Lexer: types.MarkupLexer},
+	"xml":  {BlockComment: &types.BlockComment{Start: "<!--", End: "-->"}, Lexer: types.MarkupLexer},
+	"svg":  {BlockComment: &types.BlockComment{Start: "<!--", End: "-->"}, Lexer: types.MarkupLexer},
+
+	"vue":    {BlockComment: &types.BlockComment{Start: "<!--", End: "-->"}, Lexer: types.VueLexer},
+	"svelte": {BlockComment: &types.BlockComment{Start: "<!--", End: "-->"}, Lexer: types.MarkupLexer},
+	"astro":  {BlockComment: &types.BlockComment{Start: "<!--", End: "-->"}, Lexer: types.AstroLexer},
 
-	"rb":   {LineComment: "#"},
-	"pl":   {LineComment: "#"},
-	"yml":  {LineComment: "#"},
-	"yaml": {LineComment: "#"},
-	"toml": {LineComment: "#"},
-	"ini":  {LineComment: "#", AlternateLineComment: ";"},
-	"conf": {LineComment: "#"},
-	"cfg":  {LineComment: "#"},
+	"rb":    {LineComment: "#", Strings: scriptStrings, Regex: types.RubyRegex},
+	"pl":    {LineComment: "#", Strings: scriptStrings, Regex: types.PerlRegex},
+	"yml":   {LineComment: "#", CommentWordStart: true, Strings: yamlStrings},
+	"yaml":  {LineComment: "#", CommentWordStart: true, Strings: yamlStrings},
+	"toml":  {LineComment: "#", Strings: tomlStrings},
+	"ini":   {LineComment: "#", AlternateLineComment: ";"},
+	"conf":  {LineComment: "#"},
+	"cfg":   {LineComment: "#"},
+	"nginx": {LineComment: "#", Strings: nginxStrings},
 
-	"sql": {LineComment: "--", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}},
+	"sql": {LineComment: "--", BlockComment: &types.BlockComment{Start: "/*", End: "*/"}, Strings: sqlStrings},
 
-	"dockerfile": {LineComment: "#"},
-	"makefile":   {LineComment: "#"},
+	"matlab": {LineComment: "%", BlockComment: &types.BlockComment{Start: "%{", End: "%}", Nested: true}, Strings: matlabStrings},
+
+	"env": {LineComment: "#", Lexer: types.ShellLexer},
+
+	"dockerfile": {LineComment: "#", Lexer: types.DockerfileLexer},
+	"makefile":   {LineComment: "#", Lexer: types.MakefileLexer},
 }
 
 func DetectLanguage(filename string) (types.Language, error) {
-	ext := strings.ToLower(filepath.Ext(filename))
-	if ext == "" {
-		return types.Language{}, fmt.Errorf("no file extension found")
+	key, err := languageKey(filename)
+	if err != nil {
+		return types.Language{}, err
+	}
+	return languageFor(key), nil
+}
+
+// LanguageByName returns the language a --lang value names: a languageMap
+// key such as "py", an interpreter or mode name such as "python", or a
+// display name such as "Objective-C".
+func LanguageByName(name string) (types.Language, error) {
+	key, err := lookupLanguage(name)
+	if err != nil {
+		return types.Language{}, err
 	}
+	return languageFor(key), nil
+}
 
-	ext = strings.TrimPrefix(ext, ".")
+func lookupLanguage(name string) (string, error) {
+	if key := aliasLanguage(strings.TrimPrefix(name, ".")); key != "" {
+		return key, nil
+	}
 
-	if language, ok := languageMap[ext]; ok {
-		return language, nil
+	keys := make([]string, 0, len(languageNames))
+	for key := range languageNames {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.EqualFold(languageNames[key], name) {
+			return key, nil
+		}
 	}
+	return "", fmt.Errorf("unknown language %q", name)
+}
 
-	return types.Language{}, fmt.Errorf("unsupported file extension: %s", ext)
+func languageFor(key string) types.Language {
+	language := languageMap[key]
+	language.Directives = directiveMap[key]
+	language.Keywords = keywordMap[key]
+	return language
+}
+
+var languageNames = map[string]string{
+	"lua":  "Lua",
+	"py":   "Python",
+	"sh":   "Shell",
+	"bash": "Bash",
+	"zsh":  "Zsh",
+	"fish": "Fish",
+	"ps1":  "PowerShell",
+	"r":    "R",
+
+	"js":     "JavaScript",
+	"ts":     "TypeScript",
+	"jsx":    "JSX",
+	"tsx":    "TSX",
+	"go":     "Go",
+	"c":      "C",
+	"cpp":    "C++",
+	"cc":     "C++",
+	"cxx":    "C++",
+	"h":      "C Header",
+	"hpp":    "C++ Header",
+	"objc":   "Objective-C",
+	"mm":     "Objective-C++",
+	"java":   "Java",
+	"cs":     "C#",
+	"rs":     "Rust",
+	"swift":  "Swift",
+	"kt":     "Kotlin",
+	"kts":    "Kotlin Script",
+	"dart":   "Dart",
+	"scala":  "Scala",
+	"groovy": "Groovy",
+	"gradle": "Gradle",
+	"hs":     "Haskell",
+	"ml":     "OCaml",
+	"mli":    "OCaml Interface",
+
+	"lisp": "Common Lisp",
+	"scm":  "Scheme",
+	"el":   "Emacs Lisp",
+	"clj":  "Clojure",
+	"cljs": "ClojureScript",
+	"cljc": "Clojure",
+
+	"php": "PHP",
+
+	"css":  "CSS",
+	"scss": "SCSS",
+	"sass": "Sass",
+	"less": "Less",
+
+	"html": "HTML",
+	"htm":  "HTML",
+	"xml":  "XML",
+	"svg":  "SVG",
+
+	"vue":    "Vue",
+	"svelte": "Svelte",
+	"astro":  "Astro",
+
+	"rb":    "Ruby",
+	"pl":    "Perl",
+	"yml":   "YAML",
+	"yaml":  "YAML",
+	"toml":  "TOML",
+	"ini":   "INI",
+	"conf":  "Config",
+	"cfg":   "Config",
+	"nginx": "nginx",
+
+	"sql": "SQL",
+
+	"matlab": "MATLAB",
+
+	"env": "dotenv",
+
+	"dockerfile": "Dockerfile",
+	"makefile":   "Makefile",
 }
 
 func GetLanguageName(filename string) string {
-	ext := strings.ToLower(filepath.Ext(filename))
-	ext = strings.TrimPrefix(ext, ".")
-
-	names := map[string]string{
-		"lua":  "Lua",
-		"py":   "Python",
-		"sh":   "Shell",
-		"bash": "Bash",
-		"zsh":  "Zsh",
-		"fish": "Fish",
-		"ps1":  "PowerShell",
-		"r":    "R",
-
-		"js":    "JavaScript",
-		"ts":    "TypeScript",
-		"jsx":   "JSX",
-		"tsx":   "TSX",
-		"go":    "Go",
-		"c":     "C",
-		"cpp":   "C++",
-		"cc":    "C++",
-		"cxx":   "C++",
-		"h":     "C Header",
-		"hpp":   "C++ Header",
-		"java":  "Java",
-		"cs":    "C#",
-		"rs":    "Rust",
-		"swift": "Swift",
-		"kt":    "Kotlin",
-		"kts":   "Kotlin Script",
-		"dart":  "Dart",
-		"scala": "Scala",
-		"php":   "PHP",
-
-		"css":  "CSS",
-		"scss": "SCSS",
-		"sass": "Sass",
-		"less": "Less",
-
-		"html": "HTML",
-		"htm":  "HTML",
-		"xml":  "XML",
-		"svg":  "SVG",
-
-		"rb":   "Ruby",
-		"pl":   "Perl",
-		"yml":  "YAML",
-		"yaml": "YAML",
-		"toml": "TOML",
-		"ini":  "INI",
-		"conf": "Config",
-		"cfg":  "Config",
-
-		"sql": "SQL",
-
-		"dockerfile": "Dockerfile",
-		"makefile":   "Makefile",
-	}
-
-	if name, ok := names[ext]; ok {
+	ext, err := languageKey(filename)
+	if err != nil {
+		ext = strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	}
+
+	return languageName(ext)
+}
+
+func languageName(key string) string {
+	if name, ok := languageNames[key]; ok {
 		return name
 	}
-	return ext
+	return key
 }
 
 func IsSupportedFile(filename string) bool {
-	ext := strings.ToLower(filepath.Ext(filename))
-	if ext == "" {
-		return false
-	}
-	ext = strings.TrimPrefix(ext, ".")
-	_, ok := languageMap[ext]
-	if !ok {
+	if _, err := languageKey(filename); err != nil {
 		return false
 	}
 
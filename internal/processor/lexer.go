@@ -0,0 +1,435 @@
+package processor
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+type commentKind int
+
+const (
+	lineComment commentKind = iota
+	blockComment
+	docstringComment
+)
+
+// comment is a byte span [start, end) of src that holds a single comment,
+// markers included. Line comments stop before the terminating newline. A
+// non-empty keep marks a comment the toolchain depends on and says why. doc
+// marks a comment a parser attached to a declaration, and exportedDoc one
+// documenting an exported Go declaration.
+type comment struct {
+	start       int
+	end         int
+	kind        commentKind
+	keep        string
+	doc         bool
+	exportedDoc bool
+}
+
+type commentLexer interface {
+	scan(src string) []comment
+}
+
+func newLexer(language types.Language) commentLexer {
+	switch language.Lexer {
+	case types.ShellLexer:
+		return &shellLexer{}
+	case types.GoLexer:
+		return &goLexer{}
+	case types.MarkupLexer:
+		return &markupLexer{script: "js"}
+	case types.VueLexer:
+		return &markupLexer{script: "js", blocks: true}
+	case types.AstroLexer:
+		return &markupLexer{script: "ts", frontmatter: true}
+	case types.DockerfileLexer:
+		return &dockerfileLexer{}
+	case types.MakefileLexer:
+		return &makefileLexer{}
+	}
+	return &genericLexer{language: language}
+}
+
+// genericLexer walks the whole buffer once, tracking string and block comment
+// state across newlines so that only real comment spans are reported. The
+// spans of the string and regex literals it skipped are kept in literals.
+type genericLexer struct {
+	language   types.Language
+	src        string
+	literals   []comment
+	statements []statement
+}
+
+func (l *genericLexer) scan(src string) []comment {
+	var comments, literals []comment
+	block := l.language.BlockComment
+	prev, prevLiteral := -1, false
+
+	for i := 0; i < len(src); {
+		if end, ok := l.matchString(src, i); ok {
+			literals = append(literals, comment{start: i, end: end})
+			prev, prevLiteral = end-1, true
+			i = end
+			continue
+		}
+
+		if end, ok := l.matchRegex(src, i, prev, prevLiteral); ok {
+			literals = append(literals, comment{start: i, end: end})
+			prev, prevLiteral = end-1, true
+			i = end
+			continue
+		}
+
+		if block != nil && strings.HasPrefix(src[i:], block.Start) && (!block.LongBracket || isLongBracket(src, i+len(block.Start))) {
+			end := skipBlockComment(src, i, block)
+			comments = append(comments, comment{start: i, end: end, kind: blockComment})
+			i = end
+			continue
+		}
+
+		if l.isLineCommentStart(src, i) {
+			end := strings.IndexByte(src[i:], '\n')
+			if end == -1 {
+				end = len(src)
+			} else {
+				end += i
+			}
+			comments = append(comments, comment{start: i, end: end, kind: lineComment})
+			i = end
+			continue
+		}
+
+		if c := src[i]; c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			prev, prevLiteral = i, false
+		}
+		i++
+	}
+
+	if l.language.Docstrings || l.language.EmptyBlock != "" {
+		l.statements = pythonStatements(src, literals, comments)
+	}
+	if l.language.Docstrings {
+		comments = mergeComments(comments, pythonDocstrings(src, l.statements))
+	}
+	l.src = src
+	l.literals = literals
+
+	return comments
+}
+
+func (l *genericLexer) blockHeaders() []statement {
+	if l.language.EmptyBlock == "" {
+		return nil
+	}
+	return pythonBlockHeaders(l.src, l.statements)
+}
+
+func mergeComments(a, b []comment) []comment {
+	merged := append(a, b...)
+	sort.Slice(merged, func(i, j int) bool { return merged[i].start < merged[j].start })
+	return merged
+}
+
+// skipBlockComment returns the offset just past the block comment opening at
+// src[i]. For nesting languages every inner opener must be closed first.
+func skipBlockComment(src string, i int, block *types.BlockComment) int {
+	if block.LongBracket {
+		return skipLongBracket(src, i+len(block.Start))
+	}
+
+	depth := 0
+	for i < len(src) {
+		switch {
+		case strings.HasPrefix(src[i:], block.Start) && (depth == 0 || block.Nested):
+			depth++
+			i += len(block.Start)
+		case strings.HasPrefix(src[i:], block.End):
+			depth--
+			i += len(block.End)
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return len(src)
+}
+
+func (l *genericLexer) isLineCommentStart(src string, i int) bool {
+	if l.language.CommentWordStart && i > 0 && strings.IndexByte(" \t\n", src[i-1]) == -1 {
+		return false
+	}
+	s := src[i:]
+	if l.language.LineComment != "" && strings.HasPrefix(s, l.language.LineComment) {
+		return true
+	}
+	return l.language.AlternateLineComment != "" && strings.HasPrefix(s, l.language.AlternateLineComment)
+}
+
+// defaultStrings is used for languages without their own string table: the
+// usual quotes on a single line plus backtick literals that may span lines.
+var defaultStrings = []types.StringLiteral{
+	{Start: `"`, End: `"`, Escape: '\\'},
+	{Start: "'", End: "'", Escape: '\\'},
+	{Start: "`", End: "`", Escape: '\\', Multiline: true},
+}
+
+func (l *genericLexer) stringLiterals() []types.StringLiteral {
+	if l.language.Strings != nil {
+		return l.language.Strings
+	}
+	return defaultStrings
+}
+
+// matchString reports whether a string literal opens at src[i] and, if so,
+// returns the offset just past its end. Literals are tried in table order,
+// so longer openers such as triple quotes must be listed first.
+func (l *genericLexer) matchString(src string, i int) (int, bool) {
+	for _, lit := range l.stringLiterals() {
+		if !strings.HasPrefix(src[i:], lit.Start) {
+			continue
+		}
+		if isPrefixedForm(lit.Form) && i > 0 && isIdentByte(src[i-1]) {
+			continue
+		}
+		if lit.WordStart && i > 0 && strings.IndexByte(" \t\n[{,", src[i-1]) == -1 {
+			continue
+		}
+
+		switch lit.Form {
+		case types.TemplateString:
+			return skipTemplate(src, i+len(lit.Start), lit), true
+		case types.VerbatimString:
+			return skipVerbatim(src, i+len(lit.Start), lit), true
+		case types.RustRawString:
+			if end, ok := skipRustRaw(src, i+len(lit.Start), strings.Count(lit.Start, "#")); ok {
+				return end, true
+			}
+		case types.CppRawString:
+			if end, ok := skipCppRaw(src, i+len(lit.Start)); ok {
+				return end, true
+			}
+		case types.CSharpRawString:
+			return skipCSharpRaw(src, i), true
+		case types.LongBracketString:
+			if isLongBracket(src, i) {
+				return skipLongBracket(src, i), true
+			}
+		case types.CharLiteral:
+			if end, ok := skipChar(src, i+len(lit.Start), lit); ok {
+				return end, true
+			}
+		default:
+			return skipPlain(src, i+len(lit.Start), lit), true
+		}
+	}
+	return i, false
+}
+
+// skipPlain scans to the closing delimiter. A single-line literal that hits a
+// newline is treated as unterminated and ends there, so a stray quote cannot
+// swallow the rest of the file.
+func skipPlain(src string, i int, lit types.StringLiteral) int {
+	for i < len(src) {
+		switch {
+		case lit.Escape != 0 && src[i] == lit.Escape:
+			i += 2
+		case strings.HasPrefix(src[i:], lit.End):
+			return i + len(lit.End)
+		case src[i] == '\n' && !lit.Multiline:
+			return i
+		default:
+			i++
+		}
+	}
+	return len(src)
+}
+
+// skipTemplate handles JavaScript-style template literals, whose ${...}
+// substitutions may contain further strings and templates.
+func skipTemplate(src string, i int, lit types.StringLiteral) int {
+	for i < len(src) {
+		switch {
+		case lit.Escape != 0 && src[i] == lit.Escape:
+			i += 2
+		case strings.HasPrefix(src[i:], lit.End):
+			return i + len(lit.End)
+		case strings.HasPrefix(src[i:], "${"):
+			i = skipSubstitution(src, i+2, lit)
+		default:
+			i++
+		}
+	}
+	return len(src)
+}
+
+func skipSubstitution(src string, i int, lit types.StringLiteral) int {
+	depth := 1
+	for i < len(src) {
+		switch c := src[i]; {
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case strings.HasPrefix(src[i:], lit.Start):
+			i = skipTemplate(src, i+len(lit.Start), lit)
+			continue
+		case c == '"' || c == '\'':
+			i = skipPlain(src, i+1, types.StringLiteral{End: string(c), Escape: '\\'})
+			continue
+		}
+		i++
+	}
+	return len(src)
+}
+
+// skipVerbatim handles literals where a doubled closing quote is an escaped
+// quote rather than the end, as in C# @"..." strings.
+func skipVerbatim(src string, i int, lit types.StringLiteral) int {
+	for i < len(src) {
+		if strings.HasPrefix(src[i:], lit.End) {
+			if strings.HasPrefix(src[i+len(lit.End):], lit.End) {
+				i += 2 * len(lit.End)
+				continue
+			}
+			return i + len(lit.End)
+		}
+		if src[i] == '\n' && !lit.Multiline {
+			return i
+		}
+		i++
+	}
+	return len(src)
+}
+
+// skipRustRaw expects the hashes and quote following an r or br prefix and
+// scans to the matching quote and hash run. Hashes already consumed by the
+// prefix, as in Swift's #"..."#, are passed in.
+func skipRustRaw(src string, i, hashes int) (int, bool) {
+	for i < len(src) && src[i] == '#' {
+		hashes++
+		i++
+	}
+	if i >= len(src) || src[i] != '"' {
+		return i, false
+	}
+
+	closing := `"` + strings.Repeat("#", hashes)
+	body := i + 1
+	end := strings.Index(src[body:], closing)
+	if end == -1 {
+		return len(src), true
+	}
+	return body + end + len(closing), true
+}
+
+// skipCppRaw expects the d-char sequence and parenthesis of a C++ R"delim(
+// literal and scans to the matching )delim".
+func skipCppRaw(src string, i int) (int, bool) {
+	open := strings.IndexByte(src[i:], '(')
+	if open == -1 || open > 16 || strings.ContainsAny(src[i:i+open], " \\)\t\n") {
+		return i, false
+	}
+
+	closing := ")" + src[i:i+open] + `"`
+	body := i + open + 1
+	end := strings.Index(src[body:], closing)
+	if end == -1 {
+		return len(src), true
+	}
+	return body + end + len(closing), true
+}
+
+// skipCSharpRaw handles C# 11 raw literals: three or more quotes open the
+// string and the same number of quotes close it.
+func skipCSharpRaw(src string, i int) int {
+	quotes := 0
+	for i+quotes < len(src) && src[i+quotes] == '"' {
+		quotes++
+	}
+
+	closing := strings.Repeat(`"`, quotes)
+	body := i + quotes
+	end := strings.Index(src[body:], closing)
+	if end == -1 {
+		return len(src)
+	}
+	return body + end + len(closing)
+}
+
+// isLongBracket reports whether a Lua long bracket, "[" followed by any
+// number of "=" and another "[", opens at src[i].
+func isLongBracket(src string, i int) bool {
+	if i >= len(src) || src[i] != '[' {
+		return false
+	}
+	j := i + 1
+	for j < len(src) && src[j] == '=' {
+		j++
+	}
+	return j < len(src) && src[j] == '['
+}
+
+// skipLongBracket scans from the long bracket opening at src[i] to the
+// closing bracket with the same number of "=".
+func skipLongBracket(src string, i int) int {
+	level := 0
+	for src[i+1+level] == '=' {
+		level++
+	}
+
+	closing := "]" + strings.Repeat("=", level) + "]"
+	body := i + level + 2
+	end := strings.Index(src[body:], closing)
+	if end == -1 {
+		return len(src)
+	}
+	return body + end + len(closing)
+}
+
+// isPrefixedForm reports whether a literal form must not open right after an
+// identifier, either because its prefix would be the identifier's tail or,
+// for character literals, because the quote is a prime as in Haskell's x'.
+func isPrefixedForm(form types.StringForm) bool {
+	switch form {
+	case types.RustRawString, types.CppRawString, types.VerbatimString, types.CharLiteral:
+		return true
+	}
+	return false
+}
+
+// skipChar accepts a character literal: one character or one escape
+// sequence followed by the closing quote.
+func skipChar(src string, i int, lit types.StringLiteral) (int, bool) {
+	if i >= len(src) || strings.HasPrefix(src[i:], lit.End) || src[i] == '\n' {
+		return i, false
+	}
+
+	if lit.Escape != 0 && src[i] == lit.Escape {
+		limit := min(len(src), i+12)
+		for j := i + 2; j < limit && src[j] != '\n'; j++ {
+			if strings.HasPrefix(src[j:], lit.End) {
+				return j + len(lit.End), true
+			}
+		}
+		return i, false
+	}
+
+	_, size := utf8.DecodeRuneInString(src[i:])
+	if strings.HasPrefix(src[i+size:], lit.End) {
+		return i + size + len(lit.End), true
+	}
+	return i, false
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
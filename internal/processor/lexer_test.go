@@ -0,0 +1,363 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+func TestStripCommentsMultiLine(t *testing.T) {
+	jsLanguage := types.Language{
+		LineComment:  "//",
+		BlockComment: &types.BlockComment{Start: "/*", End: "*/"},
+	}
+
+	tests := []struct {
+		name     string
+		src      string
+		language types.Language
+		cli      types.CLI
+		expected string
+	}{
+		{
+			name: "jsdoc block spanning lines",
+			src: `/**
+ * Adds two numbers.
+ * @param a first
+ */
+function add(a, b) {
+  return a + b;
+}`,
+			language: jsLanguage,
+			expected: `function add(a, b) {
+  return a + b;
+}`,
+		},
+		{
+			name: "block comment with code on both ends",
+			src: `x = 1; /* starts here
+still comment
+ends here */ y = 2;`,
+			language: jsLanguage,
+			expected: `x = 1;
+ y = 2;`,
+		},
+		{
+			name:     "line comment marker inside block comment",
+			src:      "/* see http://example.com\n   for details */\ncode();",
+			language: jsLanguage,
+			cli:      types.CLI{Kinds: []string{"line"}},
+			expected: "/* see http://example.com\n   for details */\ncode();",
+		},
+		{
+			name:     "template literal spanning lines",
+			src:      "const s = `line one\n// not a comment\n`; // comment",
+			language: jsLanguage,
+			expected: "const s = `line one\n// not a comment\n`;",
+		},
+		{
+			name:     "preserved multi-line block",
+			src:      "/*\n TODO: keep this\n*/\ncode();",
+			language: jsLanguage,
+			expected: "/*\n TODO: keep this\n*/\ncode();",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Processor{cli: tt.cli}).stripString(tt.src, tt.language, config.Default(), nil)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStripCommentsStringLiterals(t *testing.T) {
+	tests := []struct {
+		name     string
+		ext      string
+		src      string
+		expected string
+	}{
+		{
+			name:     "python triple-quoted string",
+			ext:      "py",
+			src:      "s = \"\"\"\n# not a comment\n\"\"\"  # comment",
+			expected: "s = \"\"\"\n# not a comment\n\"\"\"",
+		},
+		{
+			name:     "go raw string",
+			ext:      "go",
+			src:      "var s = `\n// not a comment\n` // comment",
+			expected: "var s = `\n// not a comment\n`",
+		},
+		{
+			name:     "javascript template with substitution",
+			ext:      "js",
+			src:      "const s = `a ${f(`/* x */`)}\n/* still string */`; /* comment */",
+			expected: "const s = `a ${f(`/* x */`)}\n/* still string */`;",
+		},
+		{
+			name:     "rust raw string with hashes",
+			ext:      "rs",
+			src:      "let s = r#\"quote \" // not a comment\"#; // comment",
+			expected: "let s = r#\"quote \" // not a comment\"#;",
+		},
+		{
+			name:     "c++ raw string with delimiter",
+			ext:      "cpp",
+			src:      "auto s = R\"x(\n)\" // not a comment\n)x\"; // comment",
+			expected: "auto s = R\"x(\n)\" // not a comment\n)x\";",
+		},
+		{
+			name:     "c# verbatim string with doubled quotes",
+			ext:      "cs",
+			src:      "var s = @\"a \"\"// b\"\"\n/* c */\"; // comment",
+			expected: "var s = @\"a \"\"// b\"\"\n/* c */\";",
+		},
+		{
+			name:     "c# raw string",
+			ext:      "cs",
+			src:      "var s = \"\"\"\n  \"// quoted\"\n  \"\"\"; // comment",
+			expected: "var s = \"\"\"\n  \"// quoted\"\n  \"\"\";",
+		},
+		{
+			name:     "lua long comments and strings",
+			ext:      "lua",
+			src:      "--[[\nmulti line\n]]\nlocal s = [[ a -- b ]] -- note\n--[==[ has ]] inside ]==]\nlocal t = [=[\n]] -- kept\n]=]\n---[[ line comment\nx = a[b[1]] -- index",
+			expected: "local s = [[ a -- b ]]\nlocal t = [=[\n]] -- kept\n]=]\nx = a[b[1]]",
+		},
+		{
+			name:     "matlab quotes and transposes",
+			ext:      "matlab",
+			src:      "%{\nblock\n%}\ns = 'it''s 50% off'; % note\nx = a' * b; % transpose\ny = \"100%\"; x = 1; % n",
+			expected: "s = 'it''s 50% off';\nx = a' * b;\ny = \"100%\"; x = 1;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Processor{}).stripString(tt.src, languageMap[tt.ext], config.Default(), nil)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStripCommentsNestedBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		ext      string
+		src      string
+		expected string
+	}{
+		{
+			name:     "rust nested block",
+			ext:      "rs",
+			src:      "let x = 1; /* outer /* inner */ still outer */ let y = 2;",
+			expected: "let x = 1;  let y = 2;",
+		},
+		{
+			name:     "kotlin nested block across lines",
+			ext:      "kt",
+			src:      "/* outer\n  /* inner */\n  still outer */\nval x = 1",
+			expected: "val x = 1",
+		},
+		{
+			name:     "haskell nested block",
+			ext:      "hs",
+			src:      "{- outer {- inner -} still outer -}\nmain = print 1 -- comment",
+			expected: "main = print 1",
+		},
+		{
+			name:     "c does not nest",
+			ext:      "c",
+			src:      "/* outer /* inner */ int x;",
+			expected: " int x;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Processor{}).stripString(tt.src, languageMap[tt.ext], config.Default(), nil)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStripCommentsQuoteRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		ext      string
+		src      string
+		expected string
+	}{
+		{
+			name:     "rust lifetimes",
+			ext:      "rs",
+			src:      "fn f<'a>(s: &'a str) -> &'a str { s } // comment",
+			expected: "fn f<'a>(s: &'a str) -> &'a str { s }",
+		},
+		{
+			name:     "rust static lifetime",
+			ext:      "rs",
+			src:      "const S: &'static str = \"x\"; // comment",
+			expected: "const S: &'static str = \"x\";",
+		},
+		{
+			name:     "rust char literal holding a comment marker",
+			ext:      "rs",
+			src:      "let c = '/'; let d = b'\\''; // comment",
+			expected: "let c = '/'; let d = b'\\'';",
+		},
+		{
+			name:     "c char literal",
+			ext:      "c",
+			src:      "char q = '\"'; /* comment */",
+			expected: "char q = '\"';",
+		},
+		{
+			name:     "c digit separators",
+			ext:      "c",
+			src:      "int a = 1'000; // comment\nf(1'000, \"a'b//c\"); /* comment */",
+			expected: "int a = 1'000;\nf(1'000, \"a'b//c\");",
+		},
+		{
+			name:     "c++ digit separators and prefixed characters",
+			ext:      "cpp",
+			src:      "auto n = 0x1'ff'ff; auto c = L'/'; // comment\nauto m = 0b1010'1010; // comment",
+			expected: "auto n = 0x1'ff'ff; auto c = L'/';\nauto m = 0b1010'1010;",
+		},
+		{
+			name:     "haskell primes",
+			ext:      "hs",
+			src:      "f' x = x' -- comment\n  where x' = 'a'",
+			expected: "f' x = x'\n  where x' = 'a'",
+		},
+		{
+			name:     "lisp quote",
+			ext:      "lisp",
+			src:      "(quote 'a) ; comment\n(list '(1 2) #\\;)",
+			expected: "(quote 'a)\n(list '(1 2) #\\;)",
+		},
+		{
+			name:     "emacs lisp character",
+			ext:      "el",
+			src:      "(insert ?\\\" \"; kept\") ; comment",
+			expected: "(insert ?\\\" \"; kept\")",
+		},
+		{
+			name:     "ocaml type variables",
+			ext:      "ml",
+			src:      "type 'a t = 'a list (* comment *)",
+			expected: "type 'a t = 'a list",
+		},
+		{
+			name:     "yaml apostrophe in plain scalar",
+			ext:      "yaml",
+			src:      "key: it's here # comment\nother: 'a # b'",
+			expected: "key: it's here\nother: 'a # b'",
+		},
+		{
+			name:     "yaml hash inside a plain scalar",
+			ext:      "yml",
+			src:      "url: http://x/#frag # comment\n# comment\ncolor: a#b",
+			expected: "url: http://x/#frag\ncolor: a#b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Processor{}).stripString(tt.src, languageMap[tt.ext], config.Default(), nil)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLexerFirstComment(t *testing.T) {
+	jsLanguage := types.Language{
+		LineComment:  "//",
+		BlockComment: &types.BlockComment{Start: "/*", End: "*/"},
+	}
+
+	tests := []struct {
+		name     string
+		line     string
+		language types.Language
+		expected int
+	}{
+		{
+			name:     "simple comment",
+			line:     "code(); // comment",
+			language: types.Language{LineComment: "//"},
+			expected: 8,
+		},
+		{
+			name:     "comment in string should be ignored",
+			line:     "console.log(\"url: http://example.com\"); // real comment",
+			language: types.Language{LineComment: "//"},
+			expected: 40,
+		},
+		{
+			name:     "no comment",
+			line:     "regular code",
+			language: types.Language{LineComment: "//"},
+			expected: -1,
+		},
+		{
+			name:     "comment marker in single quotes",
+			line:     "code('//'); // comment",
+			language: types.Language{LineComment: "//"},
+			expected: 12,
+		},
+		{
+			name:     "block comment marker in string",
+			line:     "console.log(\"/* not comment */\"); /* real comment */",
+			language: jsLanguage,
+			expected: 34,
+		},
+		{
+			name:     "hash comment marker in string - Python",
+			line:     "print(\"# not a comment\"); # real comment",
+			language: types.Language{LineComment: "#"},
+			expected: 26,
+		},
+		{
+			name:     "lua comment marker in string",
+			line:     "print(\"-- not a comment\"); -- real comment",
+			language: types.Language{LineComment: "--"},
+			expected: 27,
+		},
+		{
+			name:     "has block comment start",
+			line:     "code(); /* comment",
+			language: jsLanguage,
+			expected: 8,
+		},
+		{
+			name:     "comment markers in string should not count",
+			line:     "console.log(\"/* fake */ and // fake\");",
+			language: jsLanguage,
+			expected: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := -1
+			if comments := newLexer(tt.language).scan(tt.line); len(comments) > 0 {
+				got = comments[0].start
+			}
+			if got != tt.expected {
+				t.Errorf("first comment at %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package processor
+
+import (
+	"strings"
+)
+
+// makefileLexer follows make's comment rules. Outside recipes "#" starts a
+// comment that runs to the end of the logical line, unless it is escaped as
+// "\#" or sits inside a $(...) or ${...} reference, and define bodies are
+// kept whole. Recipe lines, which start with a tab, belong to the shell and
+// are lexed with its word-start rules.
+type makefileLexer struct{}
+
+func (l *makefileLexer) scan(src string) []comment {
+	var comments []comment
+	define := 0
+
+	for i := 0; i < len(src); {
+		end := makeLineEnd(src, i)
+		line := src[i:end]
+
+		switch word := makeDirective(line); {
+		case define > 0:
+			if word == "define" {
+				define++
+			} else if word == "endef" {
+				define--
+			}
+		case strings.HasPrefix(line, "\t"):
+			comments = append(comments, shiftComments((&shellLexer{}).scan(line), i)...)
+		default:
+			if word == "define" {
+				define++
+			}
+			if start := makeCommentStart(line); start != -1 {
+				comments = append(comments, comment{start: i + start, end: end, kind: lineComment})
+			}
+		}
+
+		i = end + 1
+	}
+
+	return comments
+}
+
+// makeLineEnd returns the offset of the newline ending the logical line that
+// starts at src[i], following backslash continuations, or len(src).
+func makeLineEnd(src string, i int) int {
+	for {
+		end := strings.IndexByte(src[i:], '\n')
+		if end == -1 {
+			return len(src)
+		}
+		end += i
+
+		line := strings.TrimSuffix(src[i:end], "\r")
+		if (len(line)-len(strings.TrimRight(line, "\\")))%2 == 0 {
+			return end
+		}
+		i = end + 1
+	}
+}
+
+// makeDirective returns "define" or "endef" when line opens or closes a
+// multi-line variable definition.
+func makeDirective(line string) string {
+	words := strings.Fields(line)
+	for len(words) > 0 && (words[0] == "override" || words[0] == "export" || words[0] == "private") {
+		words = words[1:]
+	}
+	if len(words) > 0 && (words[0] == "define" || words[0] == "endef") {
+		return words[0]
+	}
+	return ""
+}
+
+// makeCommentStart returns the offset of the "#" that starts a comment in a
+// line of make syntax, or -1.
+func makeCommentStart(line string) int {
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == '\\':
+			i += 2
+		case strings.HasPrefix(line[i:], "$$"):
+			i += 2
+		case strings.HasPrefix(line[i:], "$("):
+			i = skipMakeReference(line, i+2, '(', ')')
+		case strings.HasPrefix(line[i:], "${"):
+			i = skipMakeReference(line, i+2, '{', '}')
+		case line[i] == '#':
+			return i
+		default:
+			i++
+		}
+	}
+	return -1
+}
+
+// skipMakeReference returns the offset just past the variable reference or
+// function call whose body starts at src[i]. Make has no quoting, so only
+// the brackets are counted.
+func skipMakeReference(src string, i int, open, close byte) int {
+	depth := 1
+	for ; i < len(src); i++ {
+		switch src[i] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(src)
+}
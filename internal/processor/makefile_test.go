@@ -0,0 +1,45 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+)
+
+func TestStripCommentsMakefile(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "escaped hash and references",
+			src:      "# settings\nX = a\\#b # trailing\nY := $(subst #,-,$(X))",
+			expected: "X = a\\#b\nY := $(subst #,-,$(X))",
+		},
+		{
+			name:     "recipe lines use shell rules",
+			src:      "all:\n\t# build it\n\tcurl http://x/#frag\n\techo a#b # done",
+			expected: "all:\n\tcurl http://x/#frag\n\techo a#b",
+		},
+		{
+			name:     "comment continued with a backslash",
+			src:      "# one \\\n  two\nall:",
+			expected: "all:",
+		},
+		{
+			name:     "define bodies",
+			src:      "define HELP\n# usage: make all\nendef\n# end",
+			expected: "define HELP\n# usage: make all\nendef",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Processor{}).stripString(tt.src, languageMap["makefile"], config.Default(), nil)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
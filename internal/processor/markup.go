@@ -0,0 +1,303 @@
+package processor
+
+import (
+	"regexp"
+	"strings"
+)
+
+// markupLexer scans HTML, XML and SVG, and the Vue, Svelte and Astro
+// component formats built on them. Comments are only recognised between
+// tags, so attribute values, CDATA sections, processing instructions and
+// declarations are skipped whole. The bodies of <script> and <style>
+// elements are handed to the lexer of their language, taken from their lang
+// attribute or else script for <script> and CSS for <style>, and their
+// comments reported in place.
+//
+// With blocks set, only the top level of the file is markup, as in a Vue
+// single-file component: <template> is scanned as HTML unless its lang says
+// otherwise, and custom blocks such as <i18n> are left alone. With
+// frontmatter set, a "---" fence at the top of the file, as in Astro, is
+// TypeScript.
+type markupLexer struct {
+	script      string
+	blocks      bool
+	frontmatter bool
+}
+
+var markupAttribute = regexp.MustCompile(`(?i)\s(type|lang)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+
+// scriptTypes are the <script> types whose body is JavaScript. Any other
+// type, such as a template or JSON, holds no comments.
+var scriptTypes = map[string]bool{
+	"":                         true,
+	"module":                   true,
+	"text/javascript":          true,
+	"application/javascript":   true,
+	"text/ecmascript":          true,
+	"application/ecmascript":   true,
+	"text/x-javascript":        true,
+	"application/x-javascript": true,
+	"text/babel":               true,
+	"text/jsx":                 true,
+	"text/typescript":          true,
+}
+
+func (l *markupLexer) scan(src string) []comment {
+	var comments []comment
+
+	start := 0
+	if l.frontmatter {
+		if open, close, end, ok := frontmatter(src); ok {
+			comments = shiftComments(newLexer(languageFor("ts")).scan(src[open:close]), open)
+			start = end
+		}
+	}
+
+	if l.blocks {
+		return append(comments, shiftComments(l.scanBlocks(src[start:]), start)...)
+	}
+	return append(comments, shiftComments(l.scanMarkup(src[start:]), start)...)
+}
+
+func (l *markupLexer) scanMarkup(src string) []comment {
+	var comments []comment
+
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := skipTo(src, i+4, "-->")
+			comments = append(comments, comment{start: i, end: end, kind: blockComment})
+			i = end
+		case strings.HasPrefix(rest, "<![CDATA["):
+			i = skipTo(src, i+9, "]]>")
+		case strings.HasPrefix(rest, "<?"):
+			i = skipTo(src, i+2, "?>")
+		case strings.HasPrefix(rest, "<!"):
+			i = skipTo(src, i+2, ">")
+		case hasTagPrefix(rest, "<script"), hasTagPrefix(rest, "<style"):
+			var embedded []comment
+			embedded, i = l.scanEmbedded(src, i)
+			comments = append(comments, embedded...)
+		case len(rest) > 1 && rest[0] == '<' && (isIdentByte(rest[1]) || rest[1] == '/'):
+			i = skipTag(src, i)
+		default:
+			i++
+		}
+	}
+
+	return comments
+}
+
+// scanBlocks scans the top level of a Vue single-file component.
+func (l *markupLexer) scanBlocks(src string) []comment {
+	var comments []comment
+
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := skipTo(src, i+4, "-->")
+			comments = append(comments, comment{start: i, end: end, kind: blockComment})
+			i = end
+		case hasTagPrefix(rest, "<script"), hasTagPrefix(rest, "<style"):
+			var embedded []comment
+			embedded, i = l.scanEmbedded(src, i)
+			comments = append(comments, embedded...)
+		case len(rest) > 1 && rest[0] == '<' && isIdentByte(rest[1]):
+			name := tagName(rest)
+			open := skipTag(src, i)
+			if strings.HasSuffix(src[i:open], "/>") {
+				i = open
+				continue
+			}
+			bodyEnd := closingTag(src, open, name)
+			if name == "template" {
+				if lang := attribute(src[i:open], "lang"); lang == "" || lang == "html" {
+					comments = append(comments, shiftComments(l.scanMarkup(src[open:bodyEnd]), open)...)
+				}
+			}
+			i = skipTag(src, bodyEnd)
+		case len(rest) > 1 && rest[0] == '<' && rest[1] == '/':
+			i = skipTag(src, i)
+		default:
+			i++
+		}
+	}
+
+	return comments
+}
+
+// scanEmbedded lexes the element opening at src[i], a <script> or <style>,
+// and returns the comments in its body and the offset just past it.
+func (l *markupLexer) scanEmbedded(src string, i int) ([]comment, int) {
+	name := "script"
+	if hasTagPrefix(src[i:], "<style") {
+		name = "style"
+	}
+
+	open := skipTag(src, i)
+	tag := src[i:open]
+	if strings.HasSuffix(tag, "/>") {
+		return nil, open
+	}
+
+	bodyEnd := indexCloseTag(src, open, name)
+	closeEnd := skipTag(src, bodyEnd)
+
+	key := embeddedLanguage(name, tag, l.script)
+	if key == "" {
+		return nil, closeEnd
+	}
+
+	return shiftComments(newLexer(languageFor(key)).scan(src[open:bodyEnd]), open), closeEnd
+}
+
+// embeddedLanguage picks the languageMap key for the body of a <script> or
+// <style> tag from its lang and type attributes, or "" when the body is not
+// code, as in <script type="text/template">. script is the key for a
+// <script> with neither.
+func embeddedLanguage(name, tag, script string) string {
+	lang, typ := attribute(tag, "lang"), attribute(tag, "type")
+	if lang != "" {
+		return aliasLanguage(lang)
+	}
+	if name == "style" {
+		return "css"
+	}
+	if !scriptTypes[typ] {
+		return ""
+	}
+	if typ == "text/typescript" {
+		return "ts"
+	}
+	return script
+}
+
+// attribute returns the lower-cased value of the type or lang attribute of
+// tag, or "" when it has none.
+func attribute(tag, name string) string {
+	for _, m := range markupAttribute.FindAllStringSubmatch(tag, -1) {
+		if strings.EqualFold(m[1], name) {
+			return strings.ToLower(m[2] + m[3] + m[4])
+		}
+	}
+	return ""
+}
+
+// frontmatter finds a "---" fence on the first line of src and returns the
+// bounds of its body and the offset just past its closing fence.
+func frontmatter(src string) (open, close, end int, ok bool) {
+	first, _, found := strings.Cut(src, "\n")
+	if !found || strings.TrimSpace(first) != "---" {
+		return 0, 0, 0, false
+	}
+
+	open = len(first) + 1
+	for i := open; i < len(src); {
+		line, next := src[i:], len(src)
+		if j := strings.IndexByte(line, '\n'); j != -1 {
+			line, next = line[:j], i+j+1
+		}
+		if strings.TrimSpace(line) == "---" {
+			return open, i, i + len(line), true
+		}
+		i = next
+	}
+	return open, len(src), len(src), true
+}
+
+// shiftComments moves comments found in a slice of a buffer starting at
+// offset back to their place in the whole buffer.
+func shiftComments(comments []comment, offset int) []comment {
+	for k := range comments {
+		comments[k].start += offset
+		comments[k].end += offset
+	}
+	return comments
+}
+
+// tagName returns the lower-cased name of the tag opening s.
+func tagName(s string) string {
+	end := 1
+	for end < len(s) && (isIdentByte(s[end]) || s[end] == '-' || s[end] == ':' || s[end] == '.') {
+		end++
+	}
+	return strings.ToLower(s[1:end])
+}
+
+// closingTag returns the offset of the tag closing the named element whose
+// body starts at src[i], counting nested elements of the same name, or
+// len(src) when it is never closed.
+func closingTag(src string, i int, name string) int {
+	depth := 1
+	for i < len(src) {
+		j := strings.IndexByte(src[i:], '<')
+		if j == -1 {
+			break
+		}
+		i += j
+		switch {
+		case hasTagPrefix(src[i:], "</"+name):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case hasTagPrefix(src[i:], "<"+name) && !strings.HasSuffix(src[i:skipTag(src, i)], "/>"):
+			depth++
+		}
+		i++
+	}
+	return len(src)
+}
+
+// skipTag returns the offset just past the tag opening at src[i], skipping
+// over quoted attribute values.
+func skipTag(src string, i int) int {
+	for i++; i < len(src); i++ {
+		switch src[i] {
+		case '"', '\'':
+			end := strings.IndexByte(src[i+1:], src[i])
+			if end == -1 {
+				return len(src)
+			}
+			i += end + 1
+		case '>':
+			return i + 1
+		}
+	}
+	return len(src)
+}
+
+// skipTo returns the offset just past the first end at or after src[i], or
+// len(src) when there is none.
+func skipTo(src string, i int, end string) int {
+	if j := strings.Index(src[i:], end); j != -1 {
+		return i + j + len(end)
+	}
+	return len(src)
+}
+
+// hasTagPrefix reports whether s opens the named tag, ignoring case.
+func hasTagPrefix(s, tag string) bool {
+	if len(s) < len(tag) || !strings.EqualFold(s[:len(tag)], tag) {
+		return false
+	}
+	return len(s) == len(tag) || strings.IndexByte(" \t\r\n/>", s[len(tag)]) != -1
+}
+
+// indexCloseTag returns the offset of the first </name tag at or after
+// src[i], ignoring ASCII case, or len(src) when there is none.
+func indexCloseTag(src string, i int, name string) int {
+	for {
+		j := strings.Index(src[i:], "</")
+		if j == -1 {
+			return len(src)
+		}
+		i += j
+		if hasTagPrefix(src[i:], "</"+name) {
+			return i
+		}
+		i += 2
+	}
+}
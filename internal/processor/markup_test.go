@@ -0,0 +1,102 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+)
+
+func TestStripCommentsMarkup(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "html comments",
+			file:     "x.html",
+			src:      "<!-- header -->\n<p>hi</p> <!-- note -->\n<!--\n  multi\n-->\n<p>bye</p>",
+			expected: "<p>hi</p>\n<p>bye</p>",
+		},
+		{
+			name:     "script and style bodies",
+			file:     "x.html",
+			src:      "<script>\n// setup\nlet url = \"http://x/<!-- y -->\"; /* inline */\n</script>\n<style>\n/* reset */\nbody { margin: 0; }\n</style>",
+			expected: "<script>\nlet url = \"http://x/<!-- y -->\";\n</script>\n<style>\nbody { margin: 0; }\n</style>",
+		},
+		{
+			name:     "non-code script types",
+			file:     "x.html",
+			src:      "<script type=\"text/template\">\n// {{ name }}\n</script>\n<script type=\"module\">\nrun(); // go\n</script>",
+			expected: "<script type=\"text/template\">\n// {{ name }}\n</script>\n<script type=\"module\">\nrun();\n</script>",
+		},
+		{
+			name:     "attributes",
+			file:     "x.html",
+			src:      "<a title=\"<!-- not a comment -->\" href='//cdn'>x</a>",
+			expected: "<a title=\"<!-- not a comment -->\" href='//cdn'>x</a>",
+		},
+		{
+			name:     "conditional comments and includes",
+			file:     "x.html",
+			src:      "<!--[if IE]><p>old</p><![endif]-->\n<!--#include virtual=\"/footer.html\" -->\n<!-- plain -->",
+			expected: "<!--[if IE]><p>old</p><![endif]-->\n<!--#include virtual=\"/footer.html\" -->",
+		},
+		{
+			name:     "cdata and processing instructions",
+			file:     "x.xml",
+			src:      "<?xml version=\"1.0\"?>\n<!-- about -->\n<doc><![CDATA[ <!-- kept --> ]]></doc>\n<?php /* kept */ ?>",
+			expected: "<?xml version=\"1.0\"?>\n<doc><![CDATA[ <!-- kept --> ]]></doc>\n<?php /* kept */ ?>",
+		},
+		{
+			name:     "svg script with cdata guard",
+			file:     "x.svg",
+			src:      "<svg>\n<script>//<![CDATA[\nrun(); // comment\n//]]></script>\n</svg>",
+			expected: "<svg>\n<script>//<![CDATA[\nrun();\n//]]></script>\n</svg>",
+		},
+		{
+			name:     "non-ascii text in script",
+			file:     "x.html",
+			src:      "<script>var s = \"ȺȺȺȺȺȺȺȺȺȺȺȺȺȺȺȺ\"; // a\n</script>\n<script>var t = \"İİİİ\";\n// c\n</SCRIPT>\n<p>Ⱥ</p> <!-- d -->",
+			expected: "<script>var s = \"ȺȺȺȺȺȺȺȺȺȺȺȺȺȺȺȺ\";\n</script>\n<script>var t = \"İİİİ\";\n</SCRIPT>\n<p>Ⱥ</p>",
+		},
+		{
+			name:     "vue single-file component",
+			file:     "x.vue",
+			src:      "<!-- page -->\n<template>\n  <template v-if=\"ok\"><!-- inner --></template>\n  <p>{{ msg }}</p> <!-- note -->\n</template>\n<script setup lang=\"ts\">\n// state\nconst msg: string = \"hi\" // greeting\n</script>\n<style lang=\"scss\">\n// vars\n$c: red;\n</style>",
+			expected: "<template>\n  <template v-if=\"ok\"></template>\n  <p>{{ msg }}</p>\n</template>\n<script setup lang=\"ts\">\nconst msg: string = \"hi\"\n</script>\n<style lang=\"scss\">\n$c: red;\n</style>",
+		},
+		{
+			name:     "vue pug template and custom blocks",
+			file:     "x.vue",
+			src:      "<template lang=\"pug\">\n//- pug comment\np <!-- raw -->\n</template>\n<docs>\n<!-- usage -->\n</docs>\n<script>\n/* eslint-disable */\nexport default {} // options\n</script>",
+			expected: "<template lang=\"pug\">\n//- pug comment\np <!-- raw -->\n</template>\n<docs>\n<!-- usage -->\n</docs>\n<script>\n/* eslint-disable */\nexport default {}\n</script>",
+		},
+		{
+			name:     "svelte component",
+			file:     "x.svelte",
+			src:      "<script lang=\"ts\">\n// props\nexport let name: string;\n</script>\n<!-- svelte-ignore a11y-missing-attribute -->\n<img src={name}> <!-- avatar -->\n<style>\n/* layout */\nimg { width: 1px; }\n</style>",
+			expected: "<script lang=\"ts\">\nexport let name: string;\n</script>\n<!-- svelte-ignore a11y-missing-attribute -->\n<img src={name}>\n<style>\nimg { width: 1px; }\n</style>",
+		},
+		{
+			name:     "astro frontmatter",
+			file:     "x.astro",
+			src:      "---\n// imports\nimport Card from \"./Card.astro\"; // card\nconst title: string = \"---\";\n---\n<!-- body -->\n<Card title={title} />\n<script>\n// client\nlet n: number = 1;\n</script>",
+			expected: "---\nimport Card from \"./Card.astro\";\nconst title: string = \"---\";\n---\n<Card title={title} />\n<script>\nlet n: number = 1;\n</script>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			language, err := DetectLanguage(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := (&Processor{}).stripString(tt.src, language, config.Default(), nil)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package processor
+
+import (
+	"io/fs"
+)
+
+func copyMetadata(src, dst string, info fs.FileInfo) error {
+	return nil
+}
+
+func syncDir(dir string) {}
@@ -0,0 +1,90 @@
+//go:build unix
+
+package processor
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyMetadata gives dst the owner and extended attributes of src. Either is
+// skipped when the user may not set it or the file system does not support
+// it, as when a file owned by someone else is rewritten.
+func copyMetadata(src, dst string, info fs.FileInfo) error {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		if err := os.Lchown(dst, int(stat.Uid), int(stat.Gid)); err != nil && !errors.Is(err, fs.ErrPermission) {
+			return err
+		}
+	}
+
+	names, err := listXattrs(src)
+	if err != nil {
+		return ignoreXattrError(err)
+	}
+	for _, name := range names {
+		value, err := getXattr(src, name)
+		if err == nil {
+			err = unix.Setxattr(dst, name, value, 0)
+		}
+		if err := ignoreXattrError(err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ignoreXattrError(err error) error {
+	if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EPERM) || errors.Is(err, unix.EACCES) {
+		return nil
+	}
+	return err
+}
+
+func listXattrs(path string) ([]string, error) {
+	size, err := unix.Listxattr(path, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Listxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	start := 0
+	for i, b := range buf[:size] {
+		if b == 0 {
+			if i > start {
+				names = append(names, string(buf[start:i]))
+			}
+			start = i + 1
+		}
+	}
+	return names, nil
+}
+
+func getXattr(path, name string) ([]byte, error) {
+	size, err := unix.Getxattr(path, name, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Getxattr(path, name, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:size], nil
+}
+
+// syncDir flushes a directory so that a rename within it survives a crash.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestWriteFileAtomicKeepsXattrs(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(filename, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := unix.Setxattr(filename, "user.shush", []byte("kept"), 0); err != nil {
+		t.Skipf("extended attributes not supported here: %v", err)
+	}
+
+	if err := writeFileAtomic(filename, []byte("new")); err != nil {
+		t.Fatalf("writeFileAtomic() error = %v", err)
+	}
+
+	value, err := getXattr(filename, "user.shush")
+	if err != nil || string(value) != "kept" {
+		t.Errorf("user.shush = %q, %v; want %q", value, err, "kept")
+	}
+}
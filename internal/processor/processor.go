@@ -2,11 +2,14 @@ package processor
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/carlosarraes/shush/internal/config"
@@ -23,11 +26,22 @@ func New(cli types.CLI) *Processor {
 }
 
 func (p *Processor) Process() error {
+	cfg, configPath, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config %s: %w", configPath, err)
+	}
+	if err := RegisterLanguages(cfg); err != nil {
+		return fmt.Errorf("invalid language configuration: %w", err)
+	}
 
 	if p.cli.ChangesOnly || p.cli.Staged || p.cli.Unstaged {
 		return p.processGitChanges()
 	}
 
+	if p.cli.Path == "-" {
+		return p.processStdin()
+	}
+
 	info, err := os.Stat(p.cli.Path)
 	if os.IsNotExist(err) {
 		return fmt.Errorf("path not found: %s", p.cli.Path)
@@ -37,6 +51,9 @@ func (p *Processor) Process() error {
 	}
 
 	if info.IsDir() {
+		if p.cli.Lang != "" {
+			return fmt.Errorf("--lang applies to a single file or stdin, not a directory")
+		}
 		return p.processDirectory(p.cli.Path)
 	}
 
@@ -56,6 +73,9 @@ func (p *Processor) processDirectory(dirPath string) error {
 			if err != nil {
 				return err
 			}
+			if d.IsDir() && d.Name() == ".git" {
+				return filepath.SkipDir
+			}
 			if !d.IsDir() && IsSupportedFile(path) {
 				files = append(files, path)
 			}
@@ -103,14 +123,19 @@ func (p *Processor) processDirectory(dirPath string) error {
 }
 
 func (p *Processor) processFile(filename string) error {
-	language, err := DetectLanguage(filename)
+	lookup, name := languageKey, filename
+	if p.cli.Lang != "" {
+		lookup, name = lookupLanguage, p.cli.Lang
+	}
+	key, err := lookup(name)
 	if err != nil {
 		return err
 	}
+	language := languageFor(key)
 
 	if p.cli.Verbose {
 		fmt.Printf("Processing %s...\n", filename)
-		fmt.Printf("Detected language: %s\n", GetLanguageName(filename))
+		fmt.Printf("Detected language: %s\n", languageName(key))
 		if language.BlockComment != nil {
 			fmt.Printf("Comment types: line (%s), block (%s %s)\n",
 				language.LineComment, language.BlockComment.Start, language.BlockComment.End)
@@ -134,20 +159,34 @@ func (p *Processor) processFileInMemory(filename string, language types.Language
 		cfg = config.Default()
 	}
 
-	file, err := os.Open(filename)
+	if streamable(language) {
+		if streamed, err := p.streamFile(filename, language, cfg); streamed || err != nil {
+			return err
+		}
+	}
+
+	src, err := readSource(filename, cfg.MaxFileSize)
+	if p.skipped(filename, err) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
+	result := p.stripComments(src.lines, language, cfg, nil)
+	return p.finishFile(filename, result, func() error {
+		return writeSource(filename, src, result)
+	})
+}
 
-	if err := scanner.Err(); err != nil {
-		return err
+// finishFile reports the outcome of stripping filename and, unless the file
+// is ignored, writes the backup and then, when anything changed, the result.
+func (p *Processor) finishFile(filename string, result *stripResult, write func() error) error {
+	if result.ignored {
+		if p.cli.Verbose {
+			fmt.Printf("✓ Skipped %s (shush:ignore-file)\n", filename)
+		}
+		return nil
 	}
 
 	if p.cli.Backup {
@@ -159,35 +198,10 @@ func (p *Processor) processFileInMemory(filename string, language types.Language
 		}
 	}
 
-	modified := false
-	var processedLines []string
-
-	for _, line := range lines {
-		newLine := p.removeCommentsFromLine(line, language, cfg)
-		if newLine != line {
-			modified = true
-
-			if newLine != "" {
-				processedLines = append(processedLines, newLine)
-			}
-
-		} else {
-			processedLines = append(processedLines, line)
-		}
-	}
-
-	if modified {
-		outFile, err := os.Create(filename)
-		if err != nil {
+	if result.changed > 0 {
+		if err := write(); err != nil {
 			return err
 		}
-		defer outFile.Close()
-
-		for _, line := range processedLines {
-			if _, err := fmt.Fprintln(outFile, line); err != nil {
-				return err
-			}
-		}
 
 		if p.cli.Verbose {
 			fmt.Printf("✓ Comments removed from %s\n", filename)
@@ -199,6 +213,55 @@ func (p *Processor) processFileInMemory(filename string, language types.Language
 	return nil
 }
 
+// processStdin strips the comments from standard input, read as the --lang
+// language, and writes the result to standard output.
+func (p *Processor) processStdin() error {
+	cfg, _, err := config.Load()
+	if err != nil {
+		cfg = config.Default()
+	}
+
+	language, err := LanguageByName(p.cli.Lang)
+	if err != nil {
+		return err
+	}
+
+	in := bufio.NewReaderSize(os.Stdin, streamChunkSize+1)
+	if head, _ := in.Peek(streamChunkSize + 1); len(head) > streamChunkSize && streamable(language) {
+		_, err := p.stripStream(in, os.Stdout, language, cfg, streamChunkSize)
+		if !errors.Is(err, errNotStreamable) {
+			if err != nil {
+				return fmt.Errorf("stdin: %w", err)
+			}
+			return nil
+		}
+	}
+
+	data, err := readLimited(in, cfg.MaxFileSize)
+	if err != nil {
+		return fmt.Errorf("stdin: %w", err)
+	}
+	text, encoding, bom, err := decodeSource(data)
+	if err != nil {
+		return fmt.Errorf("stdin: %w", err)
+	}
+	src := parseSource(text)
+	src.encoding, src.bom = encoding, bom
+
+	result := p.stripComments(src.lines, language, cfg, nil)
+	if result.ignored {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	out, err := encodeSource(src.render(result.output, result.sources), src.encoding, src.bom)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
 func (p *Processor) createBackup(filename string) error {
 	backupName := filename + ".bak"
 
@@ -230,11 +293,14 @@ func (p *Processor) showPreview(filename string, language types.Language) error
 		contextLines = p.cli.ContextLines
 	}
 
-	file, err := os.Open(filename)
+	src, err := readSource(filename, cfg.MaxFileSize)
+	if p.skipped(filename, err) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	lines := src.lines
 
 	red := color.New(color.FgRed, color.CrossedOut)
 	green := color.New(color.FgGreen)
@@ -245,42 +311,13 @@ func (p *Processor) showPreview(filename string, language types.Language) error
 
 	fmt.Printf("\n%s %s\n\n", yellow.Sprint("Preview:"), filename)
 
-	scanner := bufio.NewScanner(file)
-	var lines []string
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	changedCount := 0
-	keptCount := 0
-	preservedCount := 0
+	result := p.stripComments(lines, language, cfg, nil)
+	changes := result.changes
 
-	var changes []changeInfo
-
-	for i, line := range lines {
-		lineNum := i + 1
-		newLine := p.removeCommentsFromLine(line, language, cfg)
-
-		if newLine != line {
-			changedCount++
-			if newLine == "" {
-				changes = append(changes, changeInfo{lineNum, line, newLine, "removed"})
-			} else {
-				changes = append(changes, changeInfo{lineNum, line, newLine, "modified"})
-			}
-		} else {
-			hasComment := p.lineHasComment(line, language)
-			if hasComment {
-				preservedCount++
-				changes = append(changes, changeInfo{lineNum, line, line, "preserved"})
-			} else {
-				keptCount++
-			}
-		}
+	if result.ignored {
+		fmt.Printf("%s File skipped by shush:ignore-file\n", gray.Sprint("→"))
+		fmt.Println()
+		return nil
 	}
 
 	if len(changes) == 0 {
@@ -293,26 +330,320 @@ func (p *Processor) showPreview(filename string, language types.Language) error
 		p.displayChangesWithContext(lines, changes, contextLines, red, green, nil, gray, dimGray)
 	} else {
 		for _, change := range changes {
-			lineNumStr := gray.Sprintf("%4d", change.lineNum)
-			switch change.changeType {
-			case "removed":
-				fmt.Printf("%s %s %s\n", lineNumStr, red.Sprint("-"), red.Sprint(change.oldLine))
-			case "modified":
-				fmt.Printf("%s %s %s\n", lineNumStr, red.Sprint("~"), red.Sprint(change.oldLine))
-				fmt.Printf("%s %s %s\n", lineNumStr, green.Sprint("+"), green.Sprint(change.newLine))
-			case "preserved":
-				fmt.Printf("%s %s %s\n", lineNumStr, cyan.Sprint("P"), change.oldLine)
-			}
+			printChange(change, red, green, gray)
 		}
 	}
 
 	fmt.Printf("\n%s\n", strings.Repeat("-", 50))
-	fmt.Printf("%s %d lines would be changed\n", yellow.Sprint("~"), changedCount)
-	fmt.Printf("%s %d lines would be kept\n", green.Sprint("✓"), keptCount)
-	if preservedCount > 0 {
-		fmt.Printf("%s %d comments would be preserved\n", cyan.Sprint("P"), preservedCount)
+	fmt.Printf("%s %d lines would be changed\n", yellow.Sprint("~"), result.changed)
+	fmt.Printf("%s %d lines would be kept\n", green.Sprint("✓"), result.kept)
+	if result.preserved > 0 {
+		fmt.Printf("%s %d comments would be preserved\n", cyan.Sprint("P"), result.preserved)
+	}
+	if result.guarded > 0 {
+		fmt.Printf("%s %d empty blocks would be guarded\n", yellow.Sprint("!"), result.guarded)
 	}
 	fmt.Println()
 
 	return nil
 }
+
+// stripResult is the outcome of stripComments. sources maps every output line
+// to the input line it came from.
+type stripResult struct {
+	output    []string
+	sources   []int
+	changes   []changeInfo
+	changed   int
+	kept      int
+	preserved int
+	guarded   int
+	ignored   bool
+}
+
+// lineState is the outcome for one source line. comment is the index of the
+// last comment removed from the line, or -1.
+type lineState struct {
+	text       string
+	changeType string
+	note       string
+	comment    int
+}
+
+// stripComments lexes the whole file and cuts every removable comment span out
+// of the lines it covers. Only comments touching a line accepted by inScope are
+// considered; a nil inScope means the entire file.
+func (p *Processor) stripComments(lines []string, language types.Language, cfg *config.Config, inScope func(lineNum int) bool) *stripResult {
+	return p.stripSegment(lines, language, cfg, inScope, &segment{})
+}
+
+// stripSegment is stripComments for the lines of seg, which is the whole file
+// unless the file is streamed. seg is updated with the control comment state
+// at the end of the lines.
+func (p *Processor) stripSegment(lines []string, language types.Language, cfg *config.Config, inScope func(lineNum int) bool, seg *segment) *stripResult {
+	src := strings.Join(lines, "\n")
+	starts := lineStarts(lines)
+
+	cuts := make([][]cut, len(lines))
+	hasKept := make([]bool, len(lines))
+	notes := make([]string, len(lines))
+	removedBy := make([]int, len(lines))
+	for i := range removedBy {
+		removedBy[i] = -1
+	}
+
+	lexer := newLexer(language)
+	comments := lexer.scan(src)
+	if seg.line == 0 && ignoresFile(src, comments) {
+		return &stripResult{output: lines, kept: len(lines), ignored: true}
+	}
+
+	classes := classifyComments(src, seg.line, comments, language, cfg)
+	protected, controls := controlRegions(src, comments, seg)
+
+	var redundancy []float64
+	if p.redundantMode(cfg) {
+		redundancy = redundancyScores(lines, starts, comments, src)
+	}
+	for k, c := range comments {
+		if c.kind == docstringComment && !p.removesDocstrings(cfg) {
+			continue
+		}
+
+		first := lineIndex(starts, c.start)
+		last := lineIndex(starts, max(c.start, c.end-1))
+
+		if !spanInScope(first, last, inScope) {
+			continue
+		}
+
+		keep := p.keepReason(c, classes[k], cfg)
+		if keep == "" {
+			keep = protected[k]
+		}
+		if controls[k] {
+			keep = ""
+			if cfg.KeepControlComments {
+				keep = "shush directive"
+			}
+		}
+
+		note := keep
+		informative := false
+		switch {
+		case note != "" || controls[k]:
+		case redundancy != nil:
+			informative = redundancy[k] < cfg.RedundantThreshold
+			note = redundancyNote(redundancy[k], informative)
+		case classes[k].scored && slices.Contains(p.kinds(cfg), "code"):
+			note = scoreNote(classes[k])
+		}
+
+		if keep != "" || informative || !controls[k] && !p.shouldRemove(classes[k].kind, src[c.start:c.end], cfg) {
+			for i := first; i <= last; i++ {
+				hasKept[i] = true
+				if keep != "" || notes[i] == "" {
+					notes[i] = note
+				}
+			}
+			continue
+		}
+
+		for i := first; i <= last; i++ {
+			if notes[i] == "" {
+				notes[i] = note
+			}
+			from := max(c.start, starts[i]) - starts[i]
+			to := min(c.end, starts[i]+len(lines[i])) - starts[i]
+			cuts[i] = append(cuts[i], cut{from: from, to: to})
+			removedBy[i] = k
+		}
+	}
+
+	states := make([]lineState, len(lines))
+	for i, line := range lines {
+		states[i] = lineState{text: line, comment: removedBy[i], note: notes[i]}
+
+		if len(cuts[i]) == 0 {
+			if hasKept[i] {
+				states[i].changeType = "preserved"
+			}
+			continue
+		}
+
+		newLine := strings.TrimRight(cutSpans(line, cuts[i]), " \t")
+		if strings.TrimSpace(newLine) != "" {
+			states[i].text = newLine
+			states[i].changeType = "modified"
+			continue
+		}
+
+		states[i].text = ""
+		if p.cli.PreserveLines {
+			states[i].text = leadingWhitespace(line)
+		}
+		states[i].changeType = "removed"
+	}
+
+	result := &stripResult{}
+	if headerLexer, ok := lexer.(blockHeaderLexer); ok {
+		result.guarded = p.guardEmptyBlocks(lines, states, starts, comments, headerLexer.blockHeaders(), language, cfg)
+	}
+
+	for i, state := range states {
+		lineNum := seg.line + i + 1
+		change := changeInfo{lineNum: lineNum, oldLine: lines[i], newLine: state.text, changeType: state.changeType, note: state.note}
+
+		switch state.changeType {
+		case "":
+			result.kept++
+			result.output = append(result.output, state.text)
+			result.sources = append(result.sources, i)
+		case "preserved":
+			result.preserved++
+			result.output = append(result.output, state.text)
+			result.sources = append(result.sources, i)
+			result.changes = append(result.changes, change)
+		case "removed":
+			result.changed++
+			if p.cli.PreserveLines {
+				result.output = append(result.output, state.text)
+				result.sources = append(result.sources, i)
+			}
+			result.changes = append(result.changes, change)
+		default:
+			result.changed++
+			result.output = append(result.output, state.text)
+			result.sources = append(result.sources, i)
+			result.changes = append(result.changes, change)
+		}
+	}
+
+	return result
+}
+
+func (p *Processor) shouldRemove(kind, text string, cfg *config.Config) bool {
+	if !p.kindSelected(kind, cfg) {
+		return false
+	}
+	return !cfg.ShouldPreserveComment(text)
+}
+
+// keepReason returns why a comment must stay whatever kinds are selected, or
+// "" when it may be removed.
+func (p *Processor) keepReason(c comment, class classification, cfg *config.Config) string {
+	switch {
+	case c.keep != "":
+		return c.keep
+	case c.exportedDoc && cfg.KeepExportedDocs:
+		return "exported doc"
+	case class.kind == "directive" && !slices.Contains(p.kinds(cfg), "directive"):
+		return "directive: " + class.tool
+	case class.kind == "license" && (p.cli.KeepLicense || cfg.KeepLicense) && !slices.Contains(p.kinds(cfg), "license"):
+		return "license header"
+	}
+	return ""
+}
+
+// kinds returns the kinds to remove and the kinds to keep. Flags given on the
+// command line replace the config file's selection.
+func (p *Processor) kinds(cfg *config.Config) []string {
+	kinds, _ := p.kindSelection(cfg)
+	return kinds
+}
+
+func (p *Processor) kindSelection(cfg *config.Config) (remove, keep []string) {
+	switch {
+	case len(p.cli.Kinds) > 0 || len(p.cli.KeepKinds) > 0:
+		return p.cli.Kinds, p.cli.KeepKinds
+	case p.cli.OnlyDeadCode || cfg.OnlyDeadCode:
+		return []string{"code"}, nil
+	}
+	return cfg.Kinds, cfg.KeepKinds
+}
+
+// kindSelected reports whether comments of kind are removed. With no
+// selection every kind but directives is.
+func (p *Processor) kindSelected(kind string, cfg *config.Config) bool {
+	remove, keep := p.kindSelection(cfg)
+	switch {
+	case len(remove) > 0:
+		return slices.Contains(remove, kind)
+	case len(keep) > 0:
+		return kind != "directive" && !slices.Contains(keep, kind)
+	}
+	return kind != "directive"
+}
+
+// scoreNote labels a scored comment with its class and code score, so that
+// dead_code_threshold can be tuned from the preview.
+func scoreNote(class classification) string {
+	label := "prose"
+	if class.kind == "code" {
+		label = "code"
+	}
+	return fmt.Sprintf("%s %.2f", label, class.score)
+}
+
+func redundancyNote(score float64, informative bool) string {
+	label := "redundant"
+	if informative {
+		label = "informative"
+	}
+	return fmt.Sprintf("%s %.2f", label, score)
+}
+
+func (p *Processor) redundantMode(cfg *config.Config) bool {
+	return p.cli.OnlyRedundant || cfg.OnlyRedundant
+}
+
+func (p *Processor) removesDocstrings(cfg *config.Config) bool {
+	return p.cli.Docstrings || cfg.Docstrings || slices.Contains(p.kinds(cfg), "doc")
+}
+
+func spanInScope(first, last int, inScope func(lineNum int) bool) bool {
+	if inScope == nil {
+		return true
+	}
+	for i := first; i <= last; i++ {
+		if inScope(i + 1) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineStarts returns the offset of every line in lines joined by newlines.
+func lineStarts(lines []string) []int {
+	starts := make([]int, len(lines))
+	offset := 0
+	for i, line := range lines {
+		starts[i] = offset
+		offset += len(line) + 1
+	}
+	return starts
+}
+
+func lineIndex(starts []int, offset int) int {
+	return sort.Search(len(starts), func(i int) bool { return starts[i] > offset }) - 1
+}
+
+type cut struct {
+	from int
+	to   int
+}
+
+func cutSpans(line string, cuts []cut) string {
+	var b strings.Builder
+	prev := 0
+	for _, c := range cuts {
+		b.WriteString(line[prev:c.from])
+		prev = c.to
+	}
+	b.WriteString(line[prev:])
+	return b.String()
+}
+
+func leadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
@@ -0,0 +1,117 @@
+package processor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+func TestStripCommentsScope(t *testing.T) {
+	p := &Processor{}
+	language := types.Language{
+		LineComment:  "//",
+		BlockComment: &types.BlockComment{Start: "/*", End: "*/"},
+	}
+	lines := []string{
+		"// untouched",
+		"/* changed",
+		"   block */",
+		"code(); // also untouched",
+	}
+
+	result := p.stripComments(lines, language, config.Default(), func(lineNum int) bool { return lineNum == 3 })
+	expected := []string{"// untouched", "code(); // also untouched"}
+	if !reflect.DeepEqual(result.output, expected) {
+		t.Errorf("stripComments() = %q, want %q", result.output, expected)
+	}
+	if result.changed != 2 {
+		t.Errorf("changed = %d, want 2", result.changed)
+	}
+}
+
+func TestStripCommentsSingleLine(t *testing.T) {
+	jsLanguage := types.Language{
+		LineComment:  "//",
+		BlockComment: &types.BlockComment{Start: "/*", End: "*/"},
+	}
+	pyLanguage := types.Language{LineComment: "#"}
+
+	tests := []struct {
+		name     string
+		line     string
+		language types.Language
+		cli      types.CLI
+		expected string
+	}{
+		{
+			name:     "line comment removal - JavaScript",
+			line:     "console.log('hello'); // This is a comment",
+			language: jsLanguage,
+			expected: "console.log('hello');",
+		},
+		{
+			name:     "line comment removal - Python",
+			line:     "print('hello')  # This is a comment",
+			language: pyLanguage,
+			expected: "print('hello')",
+		},
+		{
+			name:     "block comment removal - single line",
+			line:     "var x = 5; /* comment */ var y = 10;",
+			language: jsLanguage,
+			expected: "var x = 5;  var y = 10;",
+		},
+		{
+			name:     "only line comments when kinds is line",
+			line:     "code(); /* block */ // line comment",
+			language: jsLanguage,
+			cli:      types.CLI{Kinds: []string{"line"}},
+			expected: "code(); /* block */",
+		},
+		{
+			name:     "only block comments when kinds is block",
+			line:     "code(); /* block */ // line comment",
+			language: jsLanguage,
+			cli:      types.CLI{Kinds: []string{"block"}},
+			expected: "code();  // line comment",
+		},
+		{
+			name:     "preserve line when preserve lines flag set",
+			line:     "// just a comment",
+			language: jsLanguage,
+			cli:      types.CLI{PreserveLines: true},
+			expected: "",
+		},
+		{
+			name:     "no comment",
+			line:     "regular code line",
+			language: jsLanguage,
+			expected: "regular code line",
+		},
+		{
+			name:     "string literal with comment markers preserved",
+			line:     "console.log(\"/* not a comment */\"); // real comment",
+			language: jsLanguage,
+			expected: "console.log(\"/* not a comment */\");",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Processor{cli: tt.cli}).stripString(tt.line, tt.language, config.Default(), nil)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// stripString runs stripComments over src and joins the output lines back
+// into one string.
+func (p *Processor) stripString(src string, language types.Language, cfg *config.Config, inScope func(lineNum int) bool) string {
+	result := p.stripComments(strings.Split(src, "\n"), language, cfg, inScope)
+	return strings.Join(result.output, "\n")
+}
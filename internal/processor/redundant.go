@@ -0,0 +1,166 @@
+package processor
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords carry no information about the code and are ignored when
+// comparing a comment with the line it annotates.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "to": true, "of": true, "and": true,
+	"or": true, "in": true, "on": true, "at": true, "by": true, "with": true,
+	"from": true, "into": true, "is": true, "are": true, "be": true, "it": true,
+	"its": true, "this": true, "that": true, "we": true, "here": true,
+	"now": true, "then": true, "value": true, "variable": true, "line": true,
+}
+
+// operatorWords are the words comments use for what an operator or keyword
+// already says, as in "increment counter" above "counter++".
+var operatorWords = map[string][]string{
+	"++":     {"increment", "increase", "add"},
+	"--":     {"decrement", "decrease", "subtract"},
+	"+=":     {"add", "increase", "append"},
+	"-=":     {"subtract", "decrease"},
+	"=":      {"set", "assign", "store", "save", "update"},
+	":=":     {"set", "assign", "create", "declare", "initialize", "init"},
+	"(":      {"call", "invoke", "run"},
+	"return": {"return"},
+	"if":     {"check", "if", "whether"},
+	"for":    {"loop", "iterate", "each", "over", "through"},
+	"range":  {"loop", "iterate", "each", "over"},
+	"while":  {"loop", "while"},
+	"new":    {"create", "new", "instantiate", "make"},
+	"make":   {"create", "make", "initialize"},
+	"import": {"import"},
+	"print":  {"print", "log", "output"},
+	"log":    {"log", "print"},
+}
+
+// redundancyScores rates every comment by how much of it repeats the code it
+// annotates: the fraction of its content words found among the identifiers
+// and operators of that code. A trailing comment annotates the code before
+// it; a comment on its own lines annotates the next line of code.
+func redundancyScores(lines []string, starts []int, comments []comment, src string) []float64 {
+	code := codeOnlyLines(lines, starts, comments)
+
+	scores := make([]float64, len(comments))
+	for k, c := range comments {
+		first := lineIndex(starts, c.start)
+		last := lineIndex(starts, max(c.start, c.end-1))
+
+		annotated := strings.TrimSpace(lines[first][:c.start-starts[first]])
+		if annotated == "" {
+			for i := last + 1; i < len(code); i++ {
+				if strings.TrimSpace(code[i]) != "" {
+					annotated = code[i]
+					break
+				}
+			}
+		}
+
+		scores[k] = wordOverlap(src[c.start:c.end], annotated)
+	}
+	return scores
+}
+
+// codeOnlyLines returns lines with every comment cut out.
+func codeOnlyLines(lines []string, starts []int, comments []comment) []string {
+	cuts := make([][]cut, len(lines))
+	for _, c := range comments {
+		first := lineIndex(starts, c.start)
+		last := lineIndex(starts, max(c.start, c.end-1))
+		for i := first; i <= last; i++ {
+			from := max(c.start, starts[i]) - starts[i]
+			to := min(c.end, starts[i]+len(lines[i])) - starts[i]
+			cuts[i] = append(cuts[i], cut{from: from, to: to})
+		}
+	}
+
+	code := make([]string, len(lines))
+	for i, line := range lines {
+		code[i] = cutSpans(line, cuts[i])
+	}
+	return code
+}
+
+func wordOverlap(commentText, code string) float64 {
+	words := contentWords(commentText)
+	if len(words) == 0 || strings.TrimSpace(code) == "" {
+		return 0
+	}
+
+	known := make(map[string]bool)
+	for _, word := range identifierWords(code) {
+		known[word] = true
+	}
+	for operator, synonyms := range operatorWords {
+		if codeHasOperator(code, operator) {
+			for _, synonym := range synonyms {
+				known[stem(synonym)] = true
+			}
+		}
+	}
+
+	matched := 0
+	for _, word := range words {
+		if known[word] {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(words))
+}
+
+func contentWords(text string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		word = stem(strings.ToLower(word))
+		if word != "" && !stopWords[word] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// identifierWords splits the identifiers in code into lower-case words, so
+// that userCount, user_count and UserCount all yield "user" and "count".
+func identifierWords(code string) []string {
+	var words []string
+	for _, ident := range strings.FieldsFunc(code, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		start := 0
+		runes := []rune(ident)
+		for i := 1; i <= len(runes); i++ {
+			if i == len(runes) || unicode.IsUpper(runes[i]) && !unicode.IsUpper(runes[i-1]) {
+				words = append(words, stem(strings.ToLower(string(runes[start:i]))))
+				start = i
+			}
+		}
+	}
+	return words
+}
+
+func codeHasOperator(code, operator string) bool {
+	if isIdentByte(operator[0]) {
+		for _, word := range strings.FieldsFunc(code, func(r rune) bool { return r > unicode.MaxASCII || !isIdentByte(byte(r)) }) {
+			if word == operator {
+				return true
+			}
+		}
+		return false
+	}
+	if operator == "=" {
+		return strings.Contains(strings.NewReplacer("==", "", "!=", "", "<=", "", ">=", "", "=>", "").Replace(code), "=")
+	}
+	return strings.Contains(code, operator)
+}
+
+// stem strips common English suffixes so "counters" matches "counter" and
+// "increments" matches "increment".
+func stem(word string) string {
+	for _, suffix := range []string{"ing", "ed", "s"} {
+		if len(word) > len(suffix)+2 && strings.HasSuffix(word, suffix) {
+			return word[:len(word)-len(suffix)]
+		}
+	}
+	return word
+}
@@ -0,0 +1,53 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+func TestStripCommentsOnlyRedundant(t *testing.T) {
+	tests := []struct {
+		name     string
+		ext      string
+		src      string
+		scope    func(lineNum int) bool
+		expected string
+	}{
+		{
+			name:     "comment above the line it repeats",
+			ext:      "js",
+			src:      "// increment counter\ncounter++;\n// Retry because the upstream API drops requests\nretry(req);",
+			expected: "counter++;\n// Retry because the upstream API drops requests\nretry(req);",
+		},
+		{
+			name:     "trailing comment naming identifiers",
+			ext:      "go",
+			src:      "userCount := len(users) // set user count\ntimeout := 30 // seconds, per the SLA",
+			expected: "userCount := len(users)\ntimeout := 30 // seconds, per the SLA",
+		},
+		{
+			name:     "python call",
+			ext:      "py",
+			src:      "# Load the config\nconfig = load_config(path)",
+			expected: "config = load_config(path)",
+		},
+		{
+			name:     "only changed lines",
+			ext:      "js",
+			src:      "// return result\nreturn result;\n// return result\nreturn result;",
+			scope:    func(lineNum int) bool { return lineNum >= 3 },
+			expected: "// return result\nreturn result;\nreturn result;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Processor{cli: types.CLI{OnlyRedundant: true}}).stripString(tt.src, languageMap[tt.ext], config.Default(), tt.scope)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
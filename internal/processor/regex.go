@@ -0,0 +1,216 @@
+package processor
+
+import (
+	"strings"
+
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+// regexKeywords are words after which a slash starts a regex rather than a
+// division, e.g. "return /x/.test(s)" or Perl's "split /,/, $line".
+var regexKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true,
+	"new": true, "delete": true, "void": true, "throw": true, "case": true,
+	"do": true, "else": true, "yield": true, "await": true,
+
+	"if": true, "elsif": true, "unless": true, "while": true, "until": true,
+	"when": true, "and": true, "or": true, "not": true, "then": true,
+
+	"split": true, "grep": true, "map": true, "join": true, "eq": true,
+	"ne": true, "lt": true, "gt": true, "le": true, "ge": true, "cmp": true,
+}
+
+// perlQuoteOperators take a delimited argument that may contain any byte,
+// including the comment marker. The value is the number of delimited parts.
+var perlQuoteOperators = map[string]int{
+	"m": 1, "qr": 1, "q": 1, "qq": 1, "qw": 1, "qx": 1,
+	"s": 2, "tr": 2, "y": 2,
+}
+
+// rubyPercentLiterals are the %-literal types that take a delimited body.
+const rubyPercentLiterals = "rqQwWiIsx"
+
+// matchRegex reports whether a regex or quote-like literal opens at src[i].
+// prev is the offset of the last significant byte before i and prevLiteral
+// tells whether that byte closed a string or regex literal; together they
+// decide whether a slash is a regex or a division.
+func (l *genericLexer) matchRegex(src string, i, prev int, prevLiteral bool) (int, bool) {
+	switch l.language.Regex {
+	case types.JSRegex:
+		if src[i] == '/' && slashStartsRegex(src, i, prev, prevLiteral, false) {
+			return skipSlashRegex(src, i)
+		}
+	case types.RubyRegex:
+		if src[i] == '%' && i+2 < len(src) && strings.IndexByte(rubyPercentLiterals, src[i+1]) != -1 && isDelimiter(src[i+2]) && (i == 0 || !isIdentByte(src[i-1])) {
+			return skipDelimited(src, i+2, 1), true
+		}
+		if src[i] == '/' && slashStartsRegex(src, i, prev, prevLiteral, true) {
+			return skipSlashRegex(src, i)
+		}
+	case types.PerlRegex:
+		if end, ok := matchPerlQuote(src, i); ok {
+			return end, true
+		}
+		if src[i] == '/' && (prev == -1 || src[prev] != '$') && slashStartsRegex(src, i, prev, prevLiteral, true) {
+			return skipSlashRegex(src, i)
+		}
+	}
+	return i, false
+}
+
+// slashStartsRegex decides regex versus division from the previous token: a
+// slash after an operand (identifier, number, literal, closing bracket) is a
+// division, anything else starts a regex. A slash after "<" is a JSX closing
+// tag. With spacedCalls, an identifier followed by a space and then a slash
+// with no space after it is a method call taking a regex, as in Ruby's
+// "puts /x/".
+func slashStartsRegex(src string, i, prev int, prevLiteral, spacedCalls bool) bool {
+	if i+1 < len(src) && (src[i+1] == '/' || src[i+1] == '*') && !spacedCalls {
+		return false
+	}
+	if prev == -1 {
+		return true
+	}
+	if prevLiteral {
+		return false
+	}
+
+	c := src[prev]
+	switch {
+	case c == ')' || c == ']' || c == '<':
+		return false
+	case isIdentByte(c):
+		word := identBefore(src, prev+1)
+		if regexKeywords[word] {
+			return true
+		}
+		if spacedCalls && prev < i-1 && i+1 < len(src) && src[i+1] != ' ' && src[i+1] != '=' && !isDigit(word[0]) {
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// skipSlashRegex scans a /.../flags literal. Slashes inside a character
+// class do not terminate it, and a newline means it was not a regex at all.
+func skipSlashRegex(src string, i int) (int, bool) {
+	inClass := false
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return i, false
+		case '/':
+			if !inClass {
+				j++
+				for j < len(src) && isIdentByte(src[j]) {
+					j++
+				}
+				return j, true
+			}
+		}
+	}
+	return i, false
+}
+
+func matchPerlQuote(src string, i int) (int, bool) {
+	if !isIdentByte(src[i]) || i > 0 && (isIdentByte(src[i-1]) || strings.IndexByte("$@%&>", src[i-1]) != -1) {
+		return i, false
+	}
+
+	j := i
+	for j < len(src) && isIdentByte(src[j]) {
+		j++
+	}
+	parts, ok := perlQuoteOperators[src[i:j]]
+	if !ok || j >= len(src) || !isDelimiter(src[j]) || src[j] == '=' || src[j] == ',' || src[j] == ';' {
+		return i, false
+	}
+	// A bareword hash key, as in $h{y}, is not a quote.
+	if i > 0 && src[i-1] == '{' && src[j] == '}' {
+		return i, false
+	}
+	return skipDelimited(src, j, parts), true
+}
+
+// skipDelimited scans parts delimited sections starting at the opening
+// delimiter src[i]. Bracket delimiters nest and a bracketed section may be
+// followed by whitespace before the next one; other delimiters are shared
+// between consecutive parts, as in s/a/b/.
+func skipDelimited(src string, i, parts int) int {
+	for ; parts > 0 && i < len(src); parts-- {
+		open := src[i]
+		close := closingBracket(open)
+		depth := 0
+		j := i + 1
+		for ; j < len(src); j++ {
+			c := src[j]
+			if c == '\\' {
+				j++
+				continue
+			}
+			if close != open && c == open {
+				depth++
+				continue
+			}
+			if c == close {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+
+		if close == open && parts > 1 {
+			i = j
+			continue
+		}
+		i = j + 1
+		if parts > 1 {
+			for i < len(src) && (src[i] == ' ' || src[i] == '\t' || src[i] == '\n') {
+				i++
+			}
+		}
+	}
+
+	for i < len(src) && isIdentByte(src[i]) {
+		i++
+	}
+	return min(i, len(src))
+}
+
+func closingBracket(c byte) byte {
+	switch c {
+	case '(':
+		return ')'
+	case '[':
+		return ']'
+	case '{':
+		return '}'
+	case '<':
+		return '>'
+	}
+	return c
+}
+
+func isDelimiter(c byte) bool {
+	return c > ' ' && c < 0x7f && !isIdentByte(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func identBefore(src string, end int) string {
+	start := end
+	for start > 0 && isIdentByte(src[start-1]) {
+		start--
+	}
+	return src[start:end]
+}
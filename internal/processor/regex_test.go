@@ -0,0 +1,80 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+)
+
+func TestStripCommentsRegexLiterals(t *testing.T) {
+	tests := []struct {
+		name     string
+		ext      string
+		src      string
+		expected string
+	}{
+		{
+			name:     "javascript regex with slashes",
+			ext:      "js",
+			src:      "const re = /https?:\\/\\//g; // comment",
+			expected: "const re = /https?:\\/\\//g;",
+		},
+		{
+			name:     "javascript regex with class",
+			ext:      "ts",
+			src:      "if (/[/*]/.test(s)) x(); /* comment */",
+			expected: "if (/[/*]/.test(s)) x();",
+		},
+		{
+			name:     "javascript division is not a regex",
+			ext:      "js",
+			src:      "const half = total / 2; // per side / rounded",
+			expected: "const half = total / 2;",
+		},
+		{
+			name:     "jsx closing tag is not a regex",
+			ext:      "jsx",
+			src:      "return <a href=\"x\">link</a>; // comment",
+			expected: "return <a href=\"x\">link</a>;",
+		},
+		{
+			name:     "ruby regex with hash",
+			ext:      "rb",
+			src:      "ok = line =~ /[#]/ # comment",
+			expected: "ok = line =~ /[#]/",
+		},
+		{
+			name:     "ruby percent regex",
+			ext:      "rb",
+			src:      "re = %r{^#{prefix}/#x} # comment",
+			expected: "re = %r{^#{prefix}/#x}",
+		},
+		{
+			name:     "perl substitution with hash delimiter",
+			ext:      "pl",
+			src:      "$s =~ s#a#b#g; # comment",
+			expected: "$s =~ s#a#b#g;",
+		},
+		{
+			name:     "perl bareword hash keys",
+			ext:      "pl",
+			src:      "my $v = $h{y};\nmy $s = \"}}#keep\";\nmy %o = (q => 1, s=>2, y\t=> 3); # comment\n$opts{s} = $opts{q}; # more",
+			expected: "my $v = $h{y};\nmy $s = \"}}#keep\";\nmy %o = (q => 1, s=>2, y\t=> 3);\n$opts{s} = $opts{q};",
+		},
+		{
+			name:     "perl split regex",
+			ext:      "pl",
+			src:      "my @f = split /#/, $line; # comment",
+			expected: "my @f = split /#/, $line;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Processor{}).stripString(tt.src, languageMap[tt.ext], config.Default(), nil)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package processor
+
+import (
+	"strings"
+
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+// shellLexer follows POSIX word rules: "#" starts a comment only at the
+// beginning of a word, so $#, ${#var}, ${var#prefix} and mid-word hashes
+// stay code. Quoting, parameter expansion and heredoc bodies are skipped.
+type shellLexer struct{}
+
+var (
+	backtickLiteral = types.StringLiteral{End: "`", Escape: '\\', Multiline: true}
+	ansiCLiteral    = types.StringLiteral{End: "'", Escape: '\\', Multiline: true}
+)
+
+type heredoc struct {
+	delimiter string
+	stripTabs bool
+}
+
+func (l *shellLexer) scan(src string) []comment {
+	var comments []comment
+	var pending []heredoc
+	arithmetic := 0
+
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		switch {
+		case c == '\n':
+			i++
+			if len(pending) > 0 {
+				i = skipHeredocBodies(src, i, pending)
+				pending = nil
+			}
+			continue
+		case c == '\\':
+			i += 2
+			continue
+		case c == '\'':
+			i = skipUntil(src, i+1, '\'')
+			continue
+		case c == '"':
+			i = skipShellDouble(src, i+1)
+			continue
+		case c == '`':
+			i = skipPlain(src, i+1, backtickLiteral)
+			continue
+		case c == '$' && strings.HasPrefix(src[i:], "$'"):
+			i = skipPlain(src, i+2, ansiCLiteral)
+			continue
+		case c == '$' && strings.HasPrefix(src[i:], "${"):
+			i = skipShellBraces(src, i+2)
+			continue
+		case strings.HasPrefix(src[i:], "(("):
+			arithmetic++
+			i += 2
+			continue
+		case strings.HasPrefix(src[i:], "))") && arithmetic > 0:
+			arithmetic--
+			i += 2
+			continue
+		case c == '#' && isWordStart(src, i):
+			end := strings.IndexByte(src[i:], '\n')
+			if end == -1 {
+				end = len(src)
+			} else {
+				end += i
+			}
+			comments = append(comments, comment{start: i, end: end, kind: lineComment})
+			i = end
+			continue
+		case c == '<' && arithmetic == 0 && strings.HasPrefix(src[i:], "<<") && !strings.HasPrefix(src[i:], "<<<"):
+			if doc, end, ok := parseHeredoc(src, i+2); ok {
+				pending = append(pending, doc)
+				i = end
+				continue
+			}
+			i += 2
+			continue
+		}
+		i++
+	}
+
+	return comments
+}
+
+func isWordStart(src string, i int) bool {
+	return i == 0 || strings.IndexByte(" \t\n;&|()<>", src[i-1]) != -1
+}
+
+func skipUntil(src string, i int, quote byte) int {
+	end := strings.IndexByte(src[i:], quote)
+	if end == -1 {
+		return len(src)
+	}
+	return i + end + 1
+}
+
+// skipShellDouble scans a double-quoted word, including any command
+// substitutions inside it, which may hold quotes of their own.
+func skipShellDouble(src string, i int) int {
+	for i < len(src) {
+		switch {
+		case src[i] == '\\':
+			i += 2
+		case src[i] == '"':
+			return i + 1
+		case strings.HasPrefix(src[i:], "$("):
+			i = skipShellParens(src, i+2)
+		case strings.HasPrefix(src[i:], "${"):
+			i = skipShellBraces(src, i+2)
+		case src[i] == '`':
+			i = skipPlain(src, i+1, backtickLiteral)
+		default:
+			i++
+		}
+	}
+	return len(src)
+}
+
+func skipShellParens(src string, i int) int {
+	return skipShellNested(src, i, '(', ')')
+}
+
+func skipShellBraces(src string, i int) int {
+	return skipShellNested(src, i, '{', '}')
+}
+
+func skipShellNested(src string, i int, open, close byte) int {
+	depth := 1
+	for i < len(src) {
+		switch c := src[i]; c {
+		case '\\':
+			i += 2
+			continue
+		case '\'':
+			i = skipUntil(src, i+1, '\'')
+			continue
+		case '"':
+			i = skipShellDouble(src, i+1)
+			continue
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+		i++
+	}
+	return len(src)
+}
+
+// parseHeredoc reads the delimiter word after "<<" or "<<-". Quoting in the
+// word only disables expansion in the body; the delimiter itself is the word
+// with its quotes removed.
+func parseHeredoc(src string, i int) (heredoc, int, bool) {
+	doc := heredoc{}
+	if i < len(src) && src[i] == '-' {
+		doc.stripTabs = true
+		i++
+	}
+	for i < len(src) && (src[i] == ' ' || src[i] == '\t') {
+		i++
+	}
+
+	var word strings.Builder
+	for i < len(src) && strings.IndexByte(" \t\n;&|()<>", src[i]) == -1 {
+		switch c := src[i]; c {
+		case '\'', '"':
+			end := strings.IndexByte(src[i+1:], c)
+			if end == -1 {
+				return doc, i, false
+			}
+			word.WriteString(src[i+1 : i+1+end])
+			i += end + 2
+		case '\\':
+			if i+1 < len(src) {
+				word.WriteByte(src[i+1])
+			}
+			i += 2
+		default:
+			word.WriteByte(c)
+			i++
+		}
+	}
+
+	doc.delimiter = word.String()
+	return doc, i, doc.delimiter != ""
+}
+
+// skipHeredocBodies skips the bodies of the heredocs opened on the previous
+// line, in order. A body whose terminator never appears is left to be lexed
+// normally rather than swallowing the rest of the file.
+func skipHeredocBodies(src string, i int, docs []heredoc) int {
+	for _, doc := range docs {
+		end, ok := heredocEnd(src, i, doc)
+		if !ok {
+			return i
+		}
+		i = end
+	}
+	return i
+}
+
+func heredocEnd(src string, i int, doc heredoc) (int, bool) {
+	for i < len(src) {
+		lineEnd := strings.IndexByte(src[i:], '\n')
+		next := len(src)
+		if lineEnd == -1 {
+			lineEnd = len(src)
+		} else {
+			lineEnd += i
+			next = lineEnd + 1
+		}
+
+		line := strings.TrimSuffix(src[i:lineEnd], "\r")
+		if doc.stripTabs {
+			line = strings.TrimLeft(line, "\t")
+		}
+		if line == doc.delimiter {
+			return next, true
+		}
+		i = next
+	}
+	return i, false
+}
@@ -0,0 +1,60 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+)
+
+func TestStripCommentsShell(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "argument count and array length",
+			src:      "echo $# ${#array[@]} # comment",
+			expected: "echo $# ${#array[@]}",
+		},
+		{
+			name:     "prefix removal expansion",
+			src:      "name=${file#*/} # strip dir",
+			expected: "name=${file#*/}",
+		},
+		{
+			name:     "mid-word hash",
+			src:      "git log --format=%h#x # comment",
+			expected: "git log --format=%h#x",
+		},
+		{
+			name:     "hash inside command substitution in quotes",
+			src:      "echo \"$(printf '%s' \"a # b\")\" # comment",
+			expected: "echo \"$(printf '%s' \"a # b\")\"",
+		},
+		{
+			name:     "heredoc body left alone",
+			src:      "cat <<EOF\n# not a comment\nEOF\n# comment\necho done",
+			expected: "cat <<EOF\n# not a comment\nEOF\necho done",
+		},
+		{
+			name:     "quoted and tab-stripped heredoc delimiters",
+			src:      "cat <<-'END' > f # comment\n\t# body\n\tEND\necho ok",
+			expected: "cat <<-'END' > f\n\t# body\n\tEND\necho ok",
+		},
+		{
+			name:     "arithmetic shift is not a heredoc",
+			src:      "x=$((1<<2)) # comment\necho $x",
+			expected: "x=$((1<<2))\necho $x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&Processor{}).stripString(tt.src, languageMap["bash"], config.Default(), nil)
+			if got != tt.expected {
+				t.Errorf("stripComments() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errFileTooLarge = errors.New("file exceeds max_file_size")
+
+// sourceFile is a file decoded and split into lines, with what is needed to
+// write it back byte for byte: the terminator of every line, which may differ
+// from line to line, the file's encoding and whether it starts with a byte
+// order mark. The last line has an empty terminator when the file does not
+// end in a newline.
+type sourceFile struct {
+	lines    []string
+	endings  []string
+	encoding string
+	bom      bool
+}
+
+// readSource loads filename whole, so lines of any length are read, unless
+// it is larger than maxSize bytes. A maxSize of zero means no limit.
+func readSource(filename string, maxSize int64) (*sourceFile, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSize(info.Size(), maxSize); err != nil {
+		return nil, err
+	}
+
+	data, err := readLimited(f, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	text, encoding, bom, err := decodeSource(data)
+	if err != nil {
+		return nil, err
+	}
+
+	src := parseSource(text)
+	src.encoding, src.bom = encoding, bom
+	return src, nil
+}
+
+// checkSize fails with errFileTooLarge when size is over maxSize. A maxSize
+// of zero means no limit.
+func checkSize(size, maxSize int64) error {
+	if maxSize > 0 && size > maxSize {
+		return fmt.Errorf("%w (%d bytes, limit %d)", errFileTooLarge, size, maxSize)
+	}
+	return nil
+}
+
+// readLimited reads all of r, failing with errFileTooLarge once more than
+// maxSize bytes have been read. A maxSize of zero means no limit.
+func readLimited(r io.Reader, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w (more than %d bytes)", errFileTooLarge, maxSize)
+	}
+	return data, nil
+}
+
+func parseSource(data string) *sourceFile {
+	src := &sourceFile{}
+
+	for len(data) > 0 {
+		line, ending := data, ""
+		if end := strings.IndexByte(data, '\n'); end != -1 {
+			line, ending = data[:end], "\n"
+			if strings.HasSuffix(line, "\r") {
+				line, ending = line[:len(line)-1], "\r\n"
+			}
+			data = data[end+1:]
+		} else {
+			data = ""
+		}
+		src.lines = append(src.lines, line)
+		src.endings = append(src.endings, ending)
+	}
+
+	return src
+}
+
+// render joins output lines, each taken from the source line sources names,
+// back into text. Every line keeps its own terminator, except that the new
+// last line takes the original last line's, so a file without a final
+// newline still has none.
+func (s *sourceFile) render(output []string, sources []int) string {
+	var b strings.Builder
+
+	for j, line := range output {
+		b.WriteString(line)
+		ending := s.endings[sources[j]]
+		if j == len(output)-1 && len(s.endings) > 0 && s.endings[len(s.endings)-1] == "" {
+			ending = ""
+		}
+		b.WriteString(ending)
+	}
+
+	return b.String()
+}
+
+// skipped reports whether readSource rejected filename as too large or
+// binary, telling the user why; binary files are only mentioned in verbose
+// mode.
+func (p *Processor) skipped(filename string, err error) bool {
+	switch {
+	case errors.Is(err, errFileTooLarge):
+		fmt.Printf("Skipped %s: %v\n", filename, err)
+	case errors.Is(err, errBinaryFile):
+		if p.cli.Verbose {
+			fmt.Printf("Skipped %s: %v\n", filename, err)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func writeSource(filename string, src *sourceFile, result *stripResult) error {
+	data, err := encodeSource(src.render(result.output, result.sources), src.encoding, src.bom)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filename, data)
+}
+
+// writeFileAtomic replaces the contents of filename so that a reader, or a
+// crash, never sees it truncated or half written: data goes to a temporary
+// file in the same directory, is synced, and is renamed over the original.
+// A symlink is written through to its target. The file's mode is kept, and
+// so are its owner and extended attributes where the system allows it.
+func writeFileAtomic(filename string, data []byte) error {
+	f, err := createAtomic(filename)
+	if err != nil {
+		return err
+	}
+	defer f.discard()
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	return f.commit()
+}
+
+// atomicFile is the temporary file behind writeFileAtomic, for callers that
+// write the new contents piece by piece.
+type atomicFile struct {
+	*os.File
+	target    string
+	info      os.FileInfo
+	committed bool
+}
+
+func createAtomic(filename string) (*atomicFile, error) {
+	target, err := filepath.EvalSymlinks(filename)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".shush-*")
+	if err != nil {
+		return nil, err
+	}
+	return &atomicFile{File: tmp, target: target, info: info}, nil
+}
+
+// commit syncs the temporary file and renames it over the target.
+func (f *atomicFile) commit() error {
+	err := f.Sync()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = copyMetadata(f.target, f.Name(), f.info)
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), f.info.Mode())
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), f.target)
+	}
+	if err != nil {
+		return err
+	}
+
+	f.committed = true
+	syncDir(filepath.Dir(f.target))
+	return nil
+}
+
+// discard removes the temporary file unless it was committed, leaving the
+// target as it was.
+func (f *atomicFile) discard() {
+	if f.committed {
+		return
+	}
+	f.Close()
+	os.Remove(f.Name())
+}
@@ -0,0 +1,227 @@
+package processor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+func TestWriteSourceByteExact(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "crlf",
+			src:      "// comment\r\nx := 1 // trailing\r\ny := 2\r\n",
+			expected: "x := 1\r\ny := 2\r\n",
+		},
+		{
+			name:     "mixed endings",
+			src:      "a := 1\r\n// comment\nb := 2\nc := 3\r\n",
+			expected: "a := 1\r\nb := 2\nc := 3\r\n",
+		},
+		{
+			name:     "bom",
+			src:      "\xef\xbb\xbf// comment\nx := 1\n",
+			expected: "\xef\xbb\xbfx := 1\n",
+		},
+		{
+			name:     "no final newline",
+			src:      "x := 1 // trailing\r\ny := 2 // trailing",
+			expected: "x := 1\r\ny := 2",
+		},
+		{
+			name:     "last line removed without final newline",
+			src:      "x := 1\ny := 2\n// comment",
+			expected: "x := 1\ny := 2",
+		},
+		{
+			name:     "blank lines kept",
+			src:      "x := 1\r\n\r\n\r\n// comment\r\ny := 2\r\n\r\n",
+			expected: "x := 1\r\n\r\n\r\ny := 2\r\n\r\n",
+		},
+		{
+			name:     "no comments",
+			src:      "\xef\xbb\xbfx := 1\r\ny := 2",
+			expected: "\xef\xbb\xbfx := 1\r\ny := 2",
+		},
+	}
+
+	language := types.Language{LineComment: "//"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "file.txt")
+			if err := os.WriteFile(filename, []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			src, err := readSource(filename, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := &Processor{}
+			result := p.stripComments(src.lines, language, config.Default(), nil)
+			if err := writeSource(filename, src, result); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("writeSource() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadSourceLongLinesAndSizeLimit(t *testing.T) {
+	long := "var bundle = \"" + strings.Repeat("x", 1<<20) + "\"; // minified"
+	filename := filepath.Join(t.TempDir(), "bundle.js")
+	if err := os.WriteFile(filename, []byte(long+"\nrun(); // go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := readSource(filename, 0)
+	if err != nil {
+		t.Fatalf("readSource() error = %v", err)
+	}
+	p := &Processor{}
+	result := p.stripComments(src.lines, languageMap["js"], config.Default(), nil)
+	if len(result.output) != 2 || result.output[0] != strings.TrimSuffix(long, " // minified") || result.output[1] != "run();" {
+		t.Errorf("stripComments() did not strip the long line cleanly")
+	}
+
+	if _, err := readSource(filename, 1024); !errors.Is(err, errFileTooLarge) {
+		t.Errorf("readSource() with a 1024 byte limit error = %v, want errFileTooLarge", err)
+	}
+	if _, err := readSource(filename, 2<<20); err != nil {
+		t.Errorf("readSource() under the limit error = %v", err)
+	}
+
+	if _, err := readLimited(strings.NewReader(long), 1024); !errors.Is(err, errFileTooLarge) {
+		t.Errorf("readLimited() over the limit error = %v, want errFileTooLarge", err)
+	}
+	if data, err := readLimited(strings.NewReader(long), int64(len(long))); err != nil || len(data) != len(long) {
+		t.Errorf("readLimited() at the limit = %d bytes, %v", len(data), err)
+	}
+}
+
+func TestReadSourceEncodings(t *testing.T) {
+	utf16Bytes := func(bigEndian bool, text string) string {
+		var out []byte
+		for _, unit := range utf16.Encode([]rune("\ufeff" + text)) {
+			if bigEndian {
+				out = append(out, byte(unit>>8), byte(unit))
+			} else {
+				out = append(out, byte(unit), byte(unit>>8))
+			}
+		}
+		return string(out)
+	}
+
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+		err      error
+	}{
+		{
+			name:     "utf-16le with crlf",
+			src:      utf16Bytes(false, "// commentaire\r\nx := \"é\" // fin\r\n"),
+			expected: utf16Bytes(false, "x := \"é\"\r\n"),
+		},
+		{
+			name:     "utf-16be with surrogate pair",
+			src:      utf16Bytes(true, "s := \"😀\" // emoji\n"),
+			expected: utf16Bytes(true, "s := \"😀\"\n"),
+		},
+		{
+			name:     "latin-1",
+			src:      "// caf\xe9\nx := \"na\xefve\" // \xe9t\xe9\n",
+			expected: "x := \"na\xefve\"\n",
+		},
+		{
+			name: "nul byte",
+			src:  "x := 1 // comment\n\x00\x01\x02",
+			err:  errBinaryFile,
+		},
+		{
+			name: "truncated utf-16",
+			src:  utf16Bytes(false, "x := 1 // comment\n") + "\x00",
+			err:  errBinaryFile,
+		},
+	}
+
+	language := types.Language{LineComment: "//"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "file.h")
+			if err := os.WriteFile(filename, []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			src, err := readSource(filename, 0)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("readSource() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := &Processor{}
+			result := p.stripComments(src.lines, language, config.Default(), nil)
+			if err := writeSource(filename, src, result); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("writeSource() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(target, []byte("old"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link.go")
+	if err := os.Symlink("main.go", link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(link, []byte("new")); err != nil {
+		t.Fatalf("writeFileAtomic() error = %v", err)
+	}
+
+	if got, _ := os.ReadFile(target); string(got) != "new" {
+		t.Errorf("target contents = %q, want %q", got, "new")
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("symlink was replaced: %v", err)
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0o640 {
+		t.Errorf("mode = %v, want %v", info.Mode().Perm(), os.FileMode(0o640))
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("directory has %d entries, want 2 (temporary file left behind?)", len(entries))
+	}
+}
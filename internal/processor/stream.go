@@ -0,0 +1,237 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+// streamChunkSize is how much of a streamed file is read at a time. Files and
+// stdin up to this size are processed whole.
+const streamChunkSize = 1 << 20
+
+var errNotStreamable = errors.New("encoding cannot be streamed")
+
+// segment is a run of lines of a streamed file that is stripped on its own:
+// line is the number of lines before it, and off and keepNext the state of
+// the shush control comments where it starts.
+type segment struct {
+	line     int
+	off      bool
+	keepNext bool
+}
+
+// streamable reports whether files in language can be stripped a segment at
+// a time. The lexer must be able to tell where a segment may end, and nothing
+// may look across lines the way Python's docstrings and empty blocks do.
+func streamable(language types.Language) bool {
+	return language.Lexer == types.GenericLexer && !language.Docstrings && language.EmptyBlock == ""
+}
+
+// streamFile strips a file larger than streamChunkSize without loading it
+// whole, writing the result to a temporary file that replaces it when
+// anything changed. It reports false, leaving the file to the caller, when
+// the file is small enough to read whole or is UTF-16, which is not streamed.
+func (p *Processor) streamFile(filename string, language types.Language, cfg *config.Config) (bool, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return true, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return true, err
+	}
+	if info.Size() <= streamChunkSize {
+		return false, nil
+	}
+	if err := checkSize(info.Size(), cfg.MaxFileSize); p.skipped(filename, err) {
+		return true, nil
+	}
+
+	out, err := createAtomic(filename)
+	if err != nil {
+		return true, err
+	}
+	defer out.discard()
+
+	result, err := p.stripStream(bufio.NewReader(f), out, language, cfg, streamChunkSize)
+	switch {
+	case errors.Is(err, errNotStreamable):
+		return false, nil
+	case p.skipped(filename, err):
+		return true, nil
+	case err != nil:
+		return true, err
+	}
+	return true, p.finishFile(filename, result, out.commit)
+}
+
+// stripStream strips the comments from in and writes the result to w, one
+// segment of about chunkSize bytes at a time. Segments end where streamCut
+// allows, so the output is the same as stripping the input whole. A UTF-8
+// byte order mark is kept, a segment that is not valid UTF-8 is read as
+// Latin-1, and UTF-16 input fails with errNotStreamable before anything is
+// read.
+func (p *Processor) stripStream(in *bufio.Reader, w io.Writer, language types.Language, cfg *config.Config, chunkSize int) (*stripResult, error) {
+	head, _ := in.Peek(sniffLength)
+	switch {
+	case bytes.HasPrefix(head, []byte("\xff\xfe\x00\x00")), bytes.HasPrefix(head, []byte("\x00\x00\xfe\xff")):
+		return nil, fmt.Errorf("%w (UTF-32 is not supported)", errBinaryFile)
+	case bytes.HasPrefix(head, []byte("\xff\xfe")), bytes.HasPrefix(head, []byte("\xfe\xff")):
+		return nil, errNotStreamable
+	case bytes.IndexByte(head, 0) != -1:
+		return nil, errBinaryFile
+	}
+
+	out := bufio.NewWriter(w)
+	if bytes.HasPrefix(head, []byte(utf8BOM)) {
+		in.Discard(len(utf8BOM))
+		out.WriteString(utf8BOM)
+	}
+
+	result := &stripResult{}
+	seg := &segment{}
+	text, pending, lastEnding := "", "", ""
+	read := int64(0)
+	for want := chunkSize; ; {
+		more, err := readChunk(in, want)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		read += int64(len(more))
+		if cfg.MaxFileSize > 0 && read > cfg.MaxFileSize {
+			return nil, fmt.Errorf("%w (more than %d bytes)", errFileTooLarge, cfg.MaxFileSize)
+		}
+		text += more
+		eof := err == io.EOF
+
+		raw := parseSource(text)
+		cut := len(raw.lines)
+		if !eof {
+			from := 1
+			if seg.line == 0 {
+				from = max(max(ignoreFileLines, headerLines), cfg.LicenseMaxLines)
+			}
+			if cut = streamCut(raw.lines, language, from); cut == 0 {
+				want = len(text)
+				continue
+			}
+		}
+
+		size := 0
+		for i := range cut {
+			size += len(raw.lines[i]) + len(raw.endings[i])
+		}
+		chunk, encoding := text[:size], encodingUTF8
+		if !utf8.ValidString(chunk) {
+			chunk, encoding = decodeLatin1(chunk), encodingLatin1
+		}
+		src := parseSource(chunk)
+
+		stripped := p.stripSegment(src.lines, language, cfg, nil, seg)
+		if stripped.ignored {
+			result.ignored = true
+			out.WriteString(text)
+			if _, err := io.Copy(out, in); err != nil {
+				return nil, err
+			}
+			return result, out.Flush()
+		}
+
+		for j, line := range stripped.output {
+			data, err := encodeSource(line, encoding, false)
+			if err != nil {
+				return nil, err
+			}
+			out.WriteString(pending)
+			out.Write(data)
+			pending = src.endings[stripped.sources[j]]
+		}
+		if len(src.lines) > 0 {
+			lastEnding = src.endings[len(src.endings)-1]
+		}
+		result.changed += stripped.changed
+		result.kept += stripped.kept
+		result.preserved += stripped.preserved
+
+		seg.line += cut
+		text = text[size:]
+		want = chunkSize
+		if eof {
+			break
+		}
+	}
+
+	// As when the file is rendered whole, the output only ends in a
+	// newline if the input did.
+	if lastEnding != "" {
+		out.WriteString(pending)
+	}
+	return result, out.Flush()
+}
+
+// readChunk reads n bytes from in and then on to the end of the line it
+// stopped in. It returns io.EOF with the last, possibly empty, chunk.
+func readChunk(in *bufio.Reader, n int) (string, error) {
+	buf := make([]byte, n)
+	m, err := io.ReadFull(in, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return string(buf[:m]), io.EOF
+	}
+	if err != nil {
+		return "", err
+	}
+	if buf[m-1] == '\n' {
+		return string(buf), nil
+	}
+	rest, err := in.ReadString('\n')
+	return string(buf) + rest, err
+}
+
+// streamCut returns the last line, at or after line from but before the
+// final one, that a streamed file may be cut before, or 0 when there is none.
+// Lines before and after such a cut strip exactly as they do together: the
+// lexer is outside any comment, string or regex there and the slash that the
+// lexer reads by what came before cannot open the new line. The line before
+// the cut is code without a comment, so that every comment still finds the
+// code it annotates and the license header block ends before the cut.
+func streamCut(lines []string, language types.Language, from int) int {
+	src := strings.Join(lines, "\n")
+	starts := lineStarts(lines)
+	lexer := &genericLexer{language: language}
+	comments := lexer.scan(src)
+
+	crossed := make([]bool, len(lines))
+	commented := make([]bool, len(lines))
+	for _, c := range comments {
+		for i := lineIndex(starts, c.start); i <= lineIndex(starts, max(c.start, c.end-1)); i++ {
+			commented[i] = true
+		}
+	}
+	for _, span := range append(comments, lexer.literals...) {
+		for i := lineIndex(starts, span.start) + 1; i <= lineIndex(starts, span.end); i++ {
+			crossed[i] = true
+		}
+	}
+
+	for k := len(lines) - 1; k >= max(from, 1); k-- {
+		if crossed[k] || commented[k-1] || strings.TrimSpace(lines[k-1]) == "" {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimLeft(lines[k], " \t"), "/") {
+			continue
+		}
+		return k
+	}
+	return 0
+}
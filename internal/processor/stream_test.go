@@ -0,0 +1,149 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/carlosarraes/shush/internal/config"
+	"github.com/carlosarraes/shush/internal/types"
+)
+
+// stripWhole strips data the way a file small enough to read whole is.
+func stripWhole(t *testing.T, data string, language types.Language, cfg *config.Config) string {
+	t.Helper()
+	text, encoding, bom, err := decodeSource([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := parseSource(text)
+	result := (&Processor{}).stripComments(src.lines, language, cfg, nil)
+	if result.ignored {
+		return data
+	}
+	out, err := encodeSource(src.render(result.output, result.sources), encoding, bom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestStripStreamMatchesWhole(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		src      string
+	}{
+		{
+			name:     "line and block comments",
+			language: "js",
+			src:      "const a = 1; // one\n/* two\n   lines */\nconst b = 2;\n\n// three\nrun(a, b);\n",
+		},
+		{
+			name:     "regex and template literals",
+			language: "js",
+			src:      "const re = /\\/\\/ not a comment/g;\nconst t = `line\n// inside\n${x}`;\nconst d = a\n/ b / c; // divide\nfoo();\n",
+		},
+		{
+			name:     "control comments across segments",
+			language: "js",
+			src:      "a();\n// shush:off\nb(); // kept\nc();\nd(); // kept too\n// shush:on\ne(); // gone\n// shush:keep-next\n// kept\nf();\n",
+		},
+		{
+			name:     "license header",
+			language: "go",
+			src:      "// Copyright 2024 Someone\n// Licensed under the MIT License.\n\npackage main\n\nfunc main() {} // trailing\n",
+		},
+		{
+			name:     "ignore file",
+			language: "js",
+			src:      "// shush:ignore-file\nconst a = 1; // kept\nconst b = 2; // kept\n",
+		},
+		{
+			name:     "crlf without final newline",
+			language: "c",
+			src:      "int a = 1; /* one */\r\nint b = 2;\r\n// two\r\nint c = 3; // three",
+		},
+		{
+			name:     "bom and last line removed",
+			language: "rs",
+			src:      "\xef\xbb\xbffn main() {}\nlet x = 1;\n// last",
+		},
+		{
+			name:     "latin-1",
+			language: "c",
+			src:      "int caf\xe9 = 1; // \xe9t\xe9\nint b = 2;\n/* fin */\nint c = 3;\n",
+		},
+		{
+			name:     "comments only",
+			language: "js",
+			src:      "// one\n// two\n/* three */\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			language := languageMap[tt.language]
+			cfg := config.Default()
+			expected := stripWhole(t, tt.src, language, cfg)
+
+			for _, size := range []int{1, 16, 64} {
+				var out bytes.Buffer
+				_, err := (&Processor{}).stripStream(bufio.NewReader(strings.NewReader(tt.src)), &out, language, cfg, size)
+				if err != nil {
+					t.Fatalf("stripStream() with chunk size %d error = %v", size, err)
+				}
+				if out.String() != expected {
+					t.Errorf("stripStream() with chunk size %d = %q, want %q", size, out.String(), expected)
+				}
+			}
+		})
+	}
+}
+
+func TestStripStreamLimits(t *testing.T) {
+	cfg := config.Default()
+	cfg.MaxFileSize = 16
+	src := "const a = 1;\nconst b = 2;\nconst c = 3;\n"
+	_, err := (&Processor{}).stripStream(bufio.NewReader(strings.NewReader(src)), &bytes.Buffer{}, languageMap["js"], cfg, 8)
+	if !errors.Is(err, errFileTooLarge) {
+		t.Errorf("stripStream() over the limit error = %v, want errFileTooLarge", err)
+	}
+
+	utf16 := "\xff\xfe/\x00/\x00\n\x00"
+	_, err = (&Processor{}).stripStream(bufio.NewReader(strings.NewReader(utf16)), &bytes.Buffer{}, languageMap["js"], config.Default(), 8)
+	if !errors.Is(err, errNotStreamable) {
+		t.Errorf("stripStream() on UTF-16 error = %v, want errNotStreamable", err)
+	}
+}
+
+func TestProcessFileStreamsLargeFiles(t *testing.T) {
+	var b strings.Builder
+	for b.Len() <= 2*streamChunkSize {
+		b.WriteString("/* block\n   comment */\nconst s = `a\nb`; // trailing\nconst r = /x\\/y/; // regex\n")
+	}
+	src := b.String()
+	cfg := config.Default()
+	expected := stripWhole(t, src, languageMap["js"], cfg)
+
+	filename := filepath.Join(t.TempDir(), "large.js")
+	if err := os.WriteFile(filename, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := &Processor{}
+	if err := p.processFileInMemory(filename, languageMap["js"]); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != expected {
+		t.Errorf("processFileInMemory() streamed output differs from stripping the file whole")
+	}
+}
@@ -3,14 +3,19 @@ package types
 import "github.com/alecthomas/kong"
 
 type CLI struct {
-	Path          string           `arg:"" name:"path" help:"Source code file or directory to process" optional:""`
-	Inline        bool             `help:"Remove only line comments"`
-	Block         bool             `help:"Remove only block comments"`
+	Path          string           `arg:"" name:"path" help:"Source code file or directory to process, or - for stdin" optional:""`
+	Lang          string           `help:"Process the file, or stdin when path is -, as this language (e.g. python, objc, nginx)"`
+	Kinds         []string         `help:"Remove only these comment kinds (line, block, doc, directive, license, code)" sep:","`
+	KeepKinds     []string         `help:"Keep these comment kinds and remove the rest" sep:","`
+	OnlyDeadCode  bool             `help:"Remove only commented-out code"`
+	OnlyRedundant bool             `help:"Remove only comments that repeat the code they annotate"`
 	Recursive     bool             `short:"r" help:"Process directories recursively"`
 	DryRun        bool             `help:"Show what would be removed without making changes"`
 	Backup        bool             `help:"Create backup files before modification"`
 	Verbose       bool             `help:"Show detailed output"`
 	PreserveLines bool             `help:"Remove comments but preserve empty lines"`
+	Docstrings    bool             `help:"Also remove Python docstrings"`
+	KeepLicense   bool             `help:"Keep the leading license or copyright header"`
 	ContextLines  int              `short:"c" help:"Number of context lines to show in preview mode (default: from config)" default:"-1"`
 	LLM           bool             `help:"Show LLM-friendly usage guide"`
 	ChangesOnly   bool             `help:"Remove comments only from git changes (staged + unstaged + untracked)"`
@@ -26,13 +31,109 @@ type CLI struct {
 	Version       kong.VersionFlag `help:"Show version information"`
 }
 
+// CommentKinds are the classes a comment can fall into, as named by --kinds
+// and --keep-kinds.
+var CommentKinds = []string{"line", "block", "doc", "directive", "license", "code"}
+
+func IsCommentKind(kind string) bool {
+	for _, k := range CommentKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Language describes how one language spells comments and strings. With
+// CommentWordStart a line comment only opens at the start of a word, after
+// whitespace or at the start of a line, as YAML's # does.
 type Language struct {
 	LineComment          string
 	AlternateLineComment string
+	CommentWordStart     bool
 	BlockComment         *BlockComment
+	Strings              []StringLiteral
+	Docstrings           bool
+	EmptyBlock           string
+	Regex                RegexSyntax
+	Lexer                LexerKind
+	Directives           []Directive
+	DocComments          []string
+	Keywords             []string
 }
 
+// Directive is a comment that a tool reads, such as a linter suppression or
+// an encoding declaration. A comment belongs to Tool when Pattern opens its
+// body, one of its lines or the part after another comment marker inside it;
+// Anywhere directives, such as NOSONAR, may appear anywhere in it. FileStart directives only count
+// on the first line of the file, and Header directives, such as magic
+// encoding comments, only on its first two lines.
+type Directive struct {
+	Tool      string
+	Pattern   string
+	FileStart bool
+	Header    bool
+	Anywhere  bool
+}
+
+// BlockComment describes a language's block comments. A LongBracket comment
+// is Start followed by a Lua long bracket such as [[ or [==[, and runs to the
+// matching ]] or ]==]; End is then the closing bracket of level zero.
 type BlockComment struct {
-	Start string
-	End   string
+	Start       string
+	End         string
+	Nested      bool
+	LongBracket bool
 }
+
+type StringForm int
+
+const (
+	PlainString StringForm = iota
+	TemplateString
+	VerbatimString
+	RustRawString
+	CppRawString
+	CSharpRawString
+	CharLiteral
+	LongBracketString
+)
+
+// StringLiteral describes one way a language spells string or character
+// data. Plain, template and verbatim forms run from Start to End, and an
+// empty End makes Start a fixed token, such as Clojure's \" character. The
+// raw forms read their closing delimiter from the opening one. A CharLiteral
+// holds a single, possibly escaped, character; a quote that does not close
+// one, like a Rust lifetime, is plain code. A LongBracketString is a Lua
+// [[...]] or [==[...]==] string. WordStart literals only open at
+// the start of a word, so YAML's "it's" stays a plain scalar.
+type StringLiteral struct {
+	Start     string
+	End       string
+	Escape    byte
+	Multiline bool
+	WordStart bool
+	Form      StringForm
+}
+
+type RegexSyntax int
+
+const (
+	NoRegex RegexSyntax = iota
+	JSRegex
+	RubyRegex
+	PerlRegex
+)
+
+type LexerKind int
+
+const (
+	GenericLexer LexerKind = iota
+	ShellLexer
+	GoLexer
+	MarkupLexer
+	VueLexer
+	AstroLexer
+	DockerfileLexer
+	MakefileLexer
+)